@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-tent/tent/config"
+	"github.com/go-tent/tent/core"
+	"github.com/go-tent/tent/links"
+	"github.com/go-tent/tent/render"
+	"github.com/go-tent/tent/source"
+	"github.com/go-tent/tent/syncer"
+)
+
+func runValidate(ctx context.Context, args []string, w io.Writer) error {
+	var (
+		fs  = flag.NewFlagSet("validate", flag.ContinueOnError)
+		src sourceFlags
+	)
+	src.register(fs)
+	if err := src.parse(fs, args); err != nil {
+		return err
+	}
+	items, err := src.load(ctx)
+	if err != nil {
+		return err
+	}
+	root, err := src.newRoot()
+	if err != nil {
+		return err
+	}
+	switch err := root.Validate(&source.Memory{Items: items}).(type) {
+	case nil:
+	case core.Errors:
+		fmt.Fprintln(w, err)
+		return fmt.Errorf("%d of %d items are not valid", len(err), len(items))
+	default:
+		return err
+	}
+	fmt.Fprintf(w, "%d items are valid\n", len(items))
+	return nil
+}
+
+func runTree(ctx context.Context, args []string, w io.Writer) error {
+	var (
+		fs  = flag.NewFlagSet("tree", flag.ContinueOnError)
+		src sourceFlags
+	)
+	src.register(fs)
+	if err := src.parse(fs, args); err != nil {
+		return err
+	}
+	items, err := src.load(ctx)
+	if err != nil {
+		return err
+	}
+	root, err := src.decode(items)
+	if err != nil {
+		return err
+	}
+	printTree(w, root.Category, 0)
+	return nil
+}
+
+func printTree(w io.Writer, c *core.Category, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(w, "%s%s\n", indent, c)
+	for _, cmp := range c.Components {
+		fmt.Fprintf(w, "%s  %s\n", indent, cmp)
+	}
+	for i := range c.Sub {
+		printTree(w, &c.Sub[i], depth+1)
+	}
+}
+
+func runExport(ctx context.Context, args []string, w io.Writer) error {
+	var (
+		fs     = flag.NewFlagSet("export", flag.ContinueOnError)
+		src    sourceFlags
+		output = fs.String("o", "", "output file (default stdout)")
+		html   = fs.Bool("html", false, "include Segment bodies rendered as HTML")
+		base   = fs.String("base", "/", "base URL of links in HTML")
+		schema = fs.Bool("schema", false, "include the JSON Schema of Forms")
+	)
+	src.register(fs)
+	if err := src.parse(fs, args); err != nil {
+		return err
+	}
+	items, err := src.load(ctx)
+	if err != nil {
+		return err
+	}
+	root, err := src.decode(items)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	opts := exportOptions{schema: *schema}
+	if *html {
+		u := func(p string) string { return *base + p }
+		opts.renderer = render.New(render.Options{SegmentURL: u, FileURL: u})
+	}
+	v, err := newJSONCategory(root.Category, "", opts)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// jsonCategory is the exported version of a Category.
+type jsonCategory struct {
+	ID         string          `json:"id"`
+	Index      float64         `json:"index,omitempty"`
+	Meta       interface{}     `json:"meta,omitempty"`
+	Components []jsonComponent `json:"components,omitempty"`
+	Sub        []jsonCategory  `json:"sub,omitempty"`
+}
+
+// jsonComponent is the exported version of a Component.
+type jsonComponent struct {
+	Type string         `json:"type"`
+	Data core.Component `json:"data"`
+	Body string         `json:"body,omitempty"`
+	HTML string         `json:"html,omitempty"`
+	// Schema is the JSON Schema of a Form.
+	Schema *core.JSONSchema `json:"schema,omitempty"`
+}
+
+// exportOptions are the optional contents of the export.
+type exportOptions struct {
+	// renderer, if not nil, renders the Segments.
+	renderer *render.Renderer
+	schema   bool
+}
+
+// newJSONCategory converts the Category at path p.
+func newJSONCategory(c *core.Category, p string, opts exportOptions) (jsonCategory, error) {
+	v := jsonCategory{ID: c.ID, Index: c.Index}
+	if len(c.Meta) != 0 {
+		v.Meta = c.Meta
+	}
+	for _, cmp := range c.Components {
+		j := jsonComponent{
+			Type: strings.ToLower(strings.TrimPrefix(fmt.Sprintf("%T", cmp), "*core.")),
+			Data: cmp,
+		}
+		switch v := cmp.(type) {
+		case *core.Segment:
+			j.Body = string(v.Body)
+			if opts.renderer != nil {
+				html, err := opts.renderer.HTML(p, v)
+				if err != nil {
+					return jsonCategory{}, fmt.Errorf("%s: %s", path.Join(p, v.ID), err)
+				}
+				j.HTML = string(html)
+			}
+		case *core.Form:
+			if opts.schema {
+				j.Schema = v.JSONSchema()
+			}
+		}
+		v.Components = append(v.Components, j)
+	}
+	for i := range c.Sub {
+		sub, err := newJSONCategory(&c.Sub[i], path.Join(p, c.Sub[i].ID), opts)
+		if err != nil {
+			return v, err
+		}
+		v.Sub = append(v.Sub, sub)
+	}
+	return v, nil
+}
+
+func runLinks(ctx context.Context, args []string, w io.Writer) error {
+	var (
+		fs      = flag.NewFlagSet("links", flag.ContinueOnError)
+		src     sourceFlags
+		orphans = fs.Bool("orphans", false, "list the components that nothing references")
+	)
+	src.register(fs)
+	if err := src.parse(fs, args); err != nil {
+		return err
+	}
+	items, err := src.load(ctx)
+	if err != nil {
+		return err
+	}
+	root, err := src.decode(items)
+	if err != nil {
+		return err
+	}
+	report := links.Check(root.Category)
+	for _, l := range report.Broken {
+		fmt.Fprintln(w, l)
+	}
+	if *orphans {
+		for _, name := range report.Orphans {
+			fmt.Fprintf(w, "%s: not referenced\n", name)
+		}
+	}
+	if n := len(report.Broken); n != 0 {
+		return fmt.Errorf("%d broken references", n)
+	}
+	return nil
+}
+
+func runSync(ctx context.Context, args []string, w io.Writer) error {
+	var (
+		fs     = flag.NewFlagSet("sync", flag.ContinueOnError)
+		src    sourceFlags
+		dir    = fs.String("dst", "", "destination directory")
+		repo   = fs.String("github", "", "destination GitHub repository (owner/repo), token in $GITHUB_TOKEN")
+		branch = fs.String("branch", "", "destination GitHub branch (default master)")
+		remove = fs.Bool("delete", false, "delete files missing in source (directory destination only)")
+		dryRun = fs.Bool("n", false, "print the plan without applying it")
+	)
+	src.register(fs)
+	if err := src.parse(fs, args); err != nil {
+		return err
+	}
+	cfg := src.cfg
+	switch {
+	case *dir != "" && *repo != "":
+		return errors.New("-dst and -github are mutually exclusive")
+	case *dir != "":
+		cfg.Destination = &config.Destination{Dir: *dir}
+	case *repo != "":
+		parts := strings.Split(*repo, "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid repository %q, expected owner/repo", *repo)
+		}
+		cfg.Destination = &config.Destination{Github: &config.Github{
+			Owner:  parts[0],
+			Repo:   parts[1],
+			Branch: *branch,
+			Token:  os.Getenv("GITHUB_TOKEN"),
+		}}
+	case cfg.Destination == nil:
+		return errors.New("no destination, use -dst or -github")
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	dst, err := cfg.NewDestination(ctx)
+	if err != nil {
+		return err
+	}
+	var (
+		hash    = syncer.SHA1
+		current source.Source
+	)
+	if cfg.Destination.Github != nil {
+		hash = syncer.GitBlob
+	} else if *remove {
+		c := config.Config{Source: config.Source{Dir: cfg.Destination.Dir}}
+		if current, err = c.NewSource(ctx); err != nil {
+			return err
+		}
+	}
+
+	items, err := src.load(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := src.decode(items); err != nil {
+		return err
+	}
+	s := syncer.New(dst, hash)
+	plan, err := s.Plan(ctx, &source.Memory{Items: items}, current)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		for _, step := range plan {
+			if step.Action != syncer.Noop {
+				fmt.Fprintln(w, step)
+			}
+		}
+		return nil
+	}
+	report := s.Apply(ctx, plan)
+	for _, r := range report {
+		if r.Action != syncer.Noop {
+			fmt.Fprintln(w, r)
+		}
+	}
+	if f := report.Failed(); len(f) != 0 {
+		return fmt.Errorf("%d of %d operations failed", len(f), len(report))
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Command tent validates, inspects, exports and publishes Tent content.
+//
+// Usage:
+//
+//	tent <command> [flags] [dir]
+//
+// The content is read from dir (default ".") or, with -repo and -ref, from a
+// git repository. A tent.yml project file in dir, or the one given with
+// -config, sets Source, Destination and Components, the flags override it.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-tent/tent/config"
+	"github.com/go-tent/tent/core"
+	"github.com/go-tent/tent/item"
+	"github.com/go-tent/tent/source"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string, w io.Writer) error
+}
+
+var commands = []command{
+	{"validate", "checks that every item can be decoded", runValidate},
+	{"tree", "prints the decoded category tree", runTree},
+	{"sync", "pushes the content into a destination", runSync},
+	{"export", "writes the decoded tree as JSON", runExport},
+	{"links", "reports broken links and unreferenced components", runLinks},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		if err := c.run(context.Background(), os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "tent %s: %s\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if name != "help" && name != "-h" && name != "-help" {
+		fmt.Fprintf(os.Stderr, "tent: unknown command %q\n", name)
+	}
+	usage(os.Stderr)
+	os.Exit(2)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tent <command> [flags] [dir]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintln(w, "\nUse \"tent <command> -h\" for the command flags.")
+}
+
+// sourceFlags selects the Source of the content and how to decode it. The
+// project file is read from -config or from tent.yml in dir, if present, and
+// the flags override it.
+type sourceFlags struct {
+	config  string
+	repo    string
+	ref     string
+	dir     string
+	unknown string
+	ids     string
+	// cfg is the resulting configuration.
+	cfg *config.Config
+}
+
+func (s *sourceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&s.config, "config", "", "project file (default dir/"+config.FileName+", if present)")
+	fs.StringVar(&s.repo, "repo", "", "git repository URL to read instead of dir")
+	fs.StringVar(&s.ref, "ref", config.DefaultRef, "git reference used with -repo")
+	fs.StringVar(&s.unknown, "unknown", "", "policy for unknown files: error, skip (default) or attach")
+	fs.StringVar(&s.ids, "ids", "", "regular expression for IDs, \"slug\" for lowercase slugs")
+}
+
+// parse parses the flags and the optional dir argument, loading the project
+// file.
+func (s *sourceFlags) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch fs.NArg() {
+	case 0:
+		s.dir = "."
+	case 1:
+		s.dir = fs.Arg(0)
+	default:
+		return fmt.Errorf("too many arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	name := s.config
+	if name == "" {
+		name = filepath.Join(s.dir, config.FileName)
+		if _, err := os.Stat(name); err != nil {
+			name = ""
+		}
+	}
+	s.cfg = &config.Config{Source: config.Source{Dir: s.dir}}
+	if name != "" {
+		cfg, err := config.Load(name)
+		if err != nil {
+			return err
+		}
+		s.cfg = cfg
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if s.config != "" && fs.NArg() == 1 {
+		s.cfg.Source.Dir, s.cfg.Source.Git = s.dir, nil
+	}
+	if s.repo != "" {
+		s.cfg.Source.Dir, s.cfg.Source.Git = "", &config.Git{URL: s.repo, Ref: s.ref}
+	} else if g := s.cfg.Source.Git; g != nil && set["ref"] {
+		g.Ref = s.ref
+	}
+	if set["unknown"] {
+		s.cfg.Unknown = s.unknown
+	}
+	if set["ids"] {
+		s.cfg.IDPattern = s.ids
+	}
+	return s.cfg.Validate()
+}
+
+// load reads all the Items of the Source in memory.
+func (s *sourceFlags) load(ctx context.Context) ([]item.Memory, error) {
+	src, err := s.cfg.NewSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return readAll(src)
+}
+
+func readAll(src source.Source) ([]item.Memory, error) {
+	var items []item.Memory
+	for i, err := src.Next(); i != nil; i, err = src.Next() {
+		if err != nil {
+			return nil, err
+		}
+		r, err := i.Content()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", i.Name(), err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", i.Name(), err)
+		}
+		items = append(items, item.Memory{ID: i.Name(), Contents: b})
+	}
+	return items, nil
+}
+
+// newRoot returns a Root using the configuration.
+func (s *sourceFlags) newRoot() (*core.Root, error) {
+	return s.cfg.NewRoot()
+}
+
+// decode returns the Root for the given Items.
+func (s *sourceFlags) decode(items []item.Memory) (*core.Root, error) {
+	root, err := s.newRoot()
+	if err != nil {
+		return nil, err
+	}
+	if err := root.Decode(&source.Memory{Items: items}); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
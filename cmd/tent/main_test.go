@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "tent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCommands(t *testing.T) {
+	dir := testDir(t, map[string]string{
+		"a/.category.yml": "index: 1\ntitle: A",
+		"a/s_intro.md":    "---\ntitle: intro\n---\nhello",
+		".git/config":     "ignored",
+	})
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	var b bytes.Buffer
+	if err := runValidate(ctx, []string{dir}, &b); err != nil {
+		t.Fatalf("validate: %s\n%s", err, b.String())
+	}
+	b.Reset()
+	if err := runTree(ctx, []string{dir}, &b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Segment:intro") {
+		t.Fatalf("Expected segment in tree, got:\n%s", b.String())
+	}
+	b.Reset()
+	if err := runExport(ctx, []string{dir}, &b); err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Sub []struct {
+			ID         string
+			Components []struct{ Type, Body string }
+		}
+	}
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Sub) != 1 || len(m.Sub[0].Components) != 1 || m.Sub[0].Components[0].Body != "hello" {
+		t.Fatalf("Unexpected export:\n%s", b.String())
+	}
+	b.Reset()
+	if err := runExport(ctx, []string{"-html", "-base", "/docs/", dir}, &b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"html": "\u003cp\u003ehello\u003c/p\u003e\n"`) {
+		t.Fatalf("Expected html in export:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := runLinks(ctx, []string{"-orphans", dir}, &b); err != nil {
+		t.Fatalf("links: %s\n%s", err, b.String())
+	}
+	if !strings.Contains(b.String(), "a/s_intro.md: not referenced") {
+		t.Fatalf("Expected orphan, got:\n%s", b.String())
+	}
+
+	dst, err := ioutil.TempDir("", "tent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+	b.Reset()
+	if err := runSync(ctx, []string{"-dst", dst, dir}, &b); err != nil {
+		t.Fatalf("sync: %s\n%s", err, b.String())
+	}
+	if _, err := os.Stat(filepath.Join(dst, "a", "s_intro.md")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	dir := testDir(t, map[string]string{
+		"s_bad.md":     "no header",
+		"unknown.file": "",
+	})
+	defer os.RemoveAll(dir)
+	var b bytes.Buffer
+	if err := runValidate(context.Background(), []string{"-unknown", "error", dir}, &b); err == nil {
+		t.Fatal("Expected error")
+	}
+	if l := strings.Count(b.String(), "\n"); l != 2 {
+		t.Fatalf("Expected %d errors, got:\n%s", 2, b.String())
+	}
+}
+
+func TestExportSchema(t *testing.T) {
+	dir := testDir(t, map[string]string{
+		"f_survey.yml": "screens:\n- items:\n  - name: color\n    type: select\n    required: true\n    option: [red, blue]\n",
+	})
+	defer os.RemoveAll(dir)
+	var b bytes.Buffer
+	if err := runExport(context.Background(), []string{"-schema", dir}, &b); err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Components []struct {
+			Schema struct {
+				Required   []string
+				Properties map[string]struct{ Enum []string }
+			}
+		}
+	}
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Components) != 1 || len(m.Components[0].Schema.Properties["color"].Enum) != 2 {
+		t.Fatalf("Unexpected export:\n%s", b.String())
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	dir := testDir(t, map[string]string{
+		"tent.yml": "source: {dir: .}\nunknown: error\nid_pattern: slug\n",
+		"s_Bad.md": "---\ntitle: bad\n---\n",
+	})
+	defer os.RemoveAll(dir)
+	var b bytes.Buffer
+	if err := runValidate(context.Background(), []string{dir}, &b); err == nil {
+		t.Fatal("Expected error")
+	}
+	if out := b.String(); !strings.Contains(out, `invalid ID "Bad"`) || strings.Contains(out, "tent.yml") {
+		t.Fatalf("Expected only the ID error, got:\n%s", out)
+	}
+	b.Reset()
+	if err := runValidate(context.Background(), []string{"-ids", "", dir}, &b); err != nil {
+		t.Fatalf("Expected -ids to override the file, got %s\n%s", err, b.String())
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	dir := testDir(t, map[string]string{
+		"a/.schema.yml":  "fields:\n  title: {type: string, required: true}\n",
+		"a/s_intro.md":   "---\nindex: 1\n---\nhello",
+		".hidden/s_x.md": "no header",
+	})
+	defer os.RemoveAll(dir)
+	var b bytes.Buffer
+	if err := runValidate(context.Background(), []string{dir}, &b); err == nil {
+		t.Fatalf("Expected error, got:\n%s", b.String())
+	}
+	if exp := `a/s_intro.md: Segment: missing required field "title"`; strings.TrimSpace(b.String()) != exp {
+		t.Fatalf("Expected %q, got:\n%s", exp, b.String())
+	}
+}
@@ -0,0 +1,363 @@
+// Package config loads a project configuration file (tent.yml), describing
+// Source, Destination and Components.
+//
+// A sample configuration:
+//
+//	source:
+//	  dir: content
+//	  prefix: [guides/]
+//	destination:
+//	  github:
+//	    owner: go-tent
+//	    repo: website
+//	    branch: master
+//	    token: ${GITHUB_TOKEN}
+//	components: [segment, picture]
+//
+// Strings in the ${NAME} form are replaced with the environment variables.
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-tent/tent/core"
+	"github.com/go-tent/tent/destination"
+	"github.com/go-tent/tent/source"
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultRef is the git reference used when none is specified.
+const DefaultRef = "refs/remotes/origin/master"
+
+// FileName is the name of the configuration file in a project directory.
+const FileName = "tent.yml"
+
+// Components are the Components available by name.
+var Components = map[string]core.Component{
+	"segment": new(core.Segment),
+	"picture": new(core.Picture),
+	"checks":  new(core.Checks),
+	"form":    new(core.Form),
+}
+
+// Config describes a Tent project.
+type Config struct {
+	Source      Source       `yaml:"source"`
+	Destination *Destination `yaml:"destination,omitempty"`
+	// Components lists the Components names, all are used if empty.
+	Components []string `yaml:"components,omitempty"`
+	// Unknown is the policy for unknown files: error, skip (default) or attach.
+	Unknown string `yaml:"unknown,omitempty"`
+	// IDPattern is the regular expression for IDs, "slug" is core.SlugPattern.
+	IDPattern string `yaml:"id_pattern,omitempty"`
+}
+
+// Policies are the UnknownPolicies available by name.
+var Policies = map[string]core.UnknownPolicy{
+	"":       core.UnknownSkip,
+	"error":  core.UnknownError,
+	"skip":   core.UnknownSkip,
+	"attach": core.UnknownAttach,
+}
+
+// Source describes a source.Source, Dir and Git are exclusive. Hidden files
+// and directories are excluded, except for category and schema files.
+type Source struct {
+	Dir    string   `yaml:"dir,omitempty"`
+	Git    *Git     `yaml:"git,omitempty"`
+	Prefix []string `yaml:"prefix,omitempty"`
+	Suffix []string `yaml:"suffix,omitempty"`
+}
+
+// Git describes a git repository.
+type Git struct {
+	URL string `yaml:"url"`
+	Ref string `yaml:"ref,omitempty"`
+}
+
+// Destination describes a destination.Destination, Dir and Github are exclusive.
+type Destination struct {
+	Dir    string  `yaml:"dir,omitempty"`
+	Github *Github `yaml:"github,omitempty"`
+}
+
+// Github describes a repository used by destination.GithubAPI.
+type Github struct {
+	Owner  string `yaml:"owner"`
+	Repo   string `yaml:"repo"`
+	Branch string `yaml:"branch,omitempty"`
+	Token  string `yaml:"token"`
+}
+
+// Load reads the configuration file, relative paths are resolved from its directory.
+func Load(name string) (*Config, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	base := filepath.Dir(name)
+	if c.Source.Dir != "" && !filepath.IsAbs(c.Source.Dir) {
+		c.Source.Dir = filepath.Join(base, c.Source.Dir)
+	}
+	if d := c.Destination; d != nil && d.Dir != "" && !filepath.IsAbs(d.Dir) {
+		d.Dir = filepath.Join(base, d.Dir)
+	}
+	return c, nil
+}
+
+// Decode reads, expands and validates a configuration.
+func Decode(r io.Reader) (*Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(r)
+	dec.SetStrict(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	if err := expand(reflect.ValueOf(&c), os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+var envVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expand replaces the environment variables in all the strings of v.
+func expand(v reflect.Value, lookup func(string) (string, bool)) error {
+	var missing []string
+	walkStrings(v, func(s string) string {
+		return envVar.ReplaceAllStringFunc(s, func(m string) string {
+			name := envVar.FindStringSubmatch(m)[1]
+			v, ok := lookup(name)
+			if !ok {
+				missing = append(missing, name)
+			}
+			return v
+		})
+	})
+	if len(missing) != 0 {
+		return fmt.Errorf("undefined environment variables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func walkStrings(v reflect.Value, fn func(string) string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			walkStrings(v.Elem(), fn)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			walkStrings(v.Field(i), fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkStrings(v.Index(i), fn)
+		}
+	case reflect.String:
+		v.SetString(fn(v.String()))
+	}
+}
+
+// Validate checks the configuration, reporting all the problems found.
+func (c *Config) Validate() error {
+	var errs []string
+	switch s := c.Source; {
+	case s.Dir == "" && s.Git == nil:
+		errs = append(errs, "source: dir or git required")
+	case s.Dir != "" && s.Git != nil:
+		errs = append(errs, "source: dir and git are exclusive")
+	case s.Git != nil && s.Git.URL == "":
+		errs = append(errs, "source.git: url required")
+	}
+	if d := c.Destination; d != nil {
+		switch {
+		case d.Dir == "" && d.Github == nil:
+			errs = append(errs, "destination: dir or github required")
+		case d.Dir != "" && d.Github != nil:
+			errs = append(errs, "destination: dir and github are exclusive")
+		case d.Github != nil:
+			for _, f := range [][2]string{{"owner", d.Github.Owner}, {"repo", d.Github.Repo}, {"token", d.Github.Token}} {
+				if f[1] == "" {
+					errs = append(errs, fmt.Sprintf("destination.github: %s required", f[0]))
+				}
+			}
+		}
+	}
+	for i, name := range c.Components {
+		if _, ok := Components[name]; !ok {
+			errs = append(errs, fmt.Sprintf("components[%d]: unknown component %q (available: %s)", i, name, strings.Join(names(), ", ")))
+		}
+	}
+	if _, ok := Policies[c.Unknown]; !ok {
+		errs = append(errs, fmt.Sprintf("unknown: invalid policy %q (available: error, skip, attach)", c.Unknown))
+	}
+	if _, err := c.idPattern(); err != nil {
+		errs = append(errs, fmt.Sprintf("id_pattern: %s", err))
+	}
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func names() []string {
+	var list = make([]string, 0, len(Components))
+	for k := range Components {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// NewSource returns the configured Source.
+func (c *Config) NewSource(ctx context.Context) (source.Source, error) {
+	s := c.Source
+	if s.Git == nil {
+		// the File Source needs a root that is a prefix of the paths
+		dir, err := filepath.Abs(s.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("source.dir: %s", err)
+		}
+		return source.NewFile(ctx, dir, append(s.filters(dir), visible(dir))...), nil
+	}
+	repo, err := source.NewRepo(s.Git.URL)
+	if err != nil {
+		return nil, fmt.Errorf("source.git: %s", err)
+	}
+	ref := s.Git.Ref
+	if ref == "" {
+		ref = DefaultRef
+	}
+	commit, err := repo.Commit(ref)
+	if err != nil {
+		return nil, fmt.Errorf("source.git: %s: %s", ref, err)
+	}
+	return source.NewGit(ctx, commit, append(s.filters(""), visible(""))...)
+}
+
+// visible excludes the files and directories starting with a dot, except for
+// category and schema files, and the configuration file in root.
+func visible(root string) source.PathFilter {
+	return func(s string) bool {
+		s = strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(s, root)), "/")
+		if s == FileName {
+			return false
+		}
+		for _, p := range strings.Split(s, "/") {
+			if strings.HasPrefix(p, ".") && p != ".category.yml" && p != ".schema.yml" {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// filters returns the PathFilters, dir is prepended to the prefixes.
+func (s Source) filters(dir string) []source.PathFilter {
+	var list []source.PathFilter
+	if len(s.Prefix) != 0 {
+		var prefixes []source.PathFilter
+		for _, p := range s.Prefix {
+			if dir != "" {
+				p = filepath.Join(dir, p) + p[len(strings.TrimSuffix(p, "/")):]
+			}
+			prefixes = append(prefixes, source.FilterPrefix(p))
+		}
+		list = append(list, anyFilter(prefixes))
+	}
+	if len(s.Suffix) != 0 {
+		var suffixes []source.PathFilter
+		for _, p := range s.Suffix {
+			suffixes = append(suffixes, source.FilterSuffix(p))
+		}
+		list = append(list, anyFilter(suffixes))
+	}
+	return list
+}
+
+// anyFilter returns a PathFilter that matches if one of the filters does.
+func anyFilter(filters []source.PathFilter) source.PathFilter {
+	return func(s string) bool {
+		for _, f := range filters {
+			if f(s) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NewDestination returns the configured Destination.
+func (c *Config) NewDestination(ctx context.Context) (destination.Destination, error) {
+	d := c.Destination
+	if d == nil {
+		return nil, errors.New("destination: not configured")
+	}
+	if d.Github == nil {
+		return destination.NewFile(d.Dir), nil
+	}
+	client := github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: d.Github.Token},
+	)))
+	branch := d.Github.Branch
+	if branch == "" {
+		branch = "master"
+	}
+	return destination.NewGihubAPI(ctx, client, destination.RepoCfg{
+		Owner:  d.Github.Owner,
+		Repo:   d.Github.Repo,
+		Branch: branch,
+	}), nil
+}
+
+func (c *Config) idPattern() (*regexp.Regexp, error) {
+	switch c.IDPattern {
+	case "":
+		return nil, nil
+	case "slug":
+		return core.SlugPattern, nil
+	default:
+		return regexp.Compile(c.IDPattern)
+	}
+}
+
+// NewRoot returns a Root with the configured Components and Options.
+func (c *Config) NewRoot() (*core.Root, error) {
+	p, err := c.idPattern()
+	if err != nil {
+		return nil, err
+	}
+	opts := core.Options{Unknown: Policies[c.Unknown], IDPattern: p}
+	if len(c.Components) == 0 {
+		return core.NewRootOptions(opts, core.Components...)
+	}
+	var list = make([]core.Component, 0, len(c.Components))
+	for _, name := range c.Components {
+		cmp, ok := Components[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown component %q", name)
+		}
+		list = append(list, cmp)
+	}
+	return core.NewRootOptions(opts, list...)
+}
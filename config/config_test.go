@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-tent/tent/destination"
+)
+
+func TestDecode(t *testing.T) {
+	os.Setenv("TENT_TEST_TOKEN", "secret")
+	defer os.Unsetenv("TENT_TEST_TOKEN")
+	c, err := Decode(strings.NewReader(`
+source:
+  git:
+    url: https://github.com/go-tent/tent
+  prefix: [core/]
+destination:
+  github:
+    owner: go-tent
+    repo: website
+    token: ${TENT_TEST_TOKEN}
+components: [segment, form]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok := c.Destination.Github.Token; tok != "secret" {
+		t.Fatalf("Expected %q token, got %q", "secret", tok)
+	}
+	dst, err := c.NewDestination(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dst.(*destination.GithubAPI); !ok {
+		t.Fatalf("Expected %T, got %T", new(destination.GithubAPI), dst)
+	}
+	if _, err := c.NewRoot(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	testCases := map[string][]string{
+		"source:\n  dir: a\n  git: {url: b}":                   {"source: dir and git are exclusive"},
+		"source: {}\ncomponents: [segmnet]":                    {"source: dir or git required", `unknown component "segmnet"`},
+		"source: {dir: a}\ndestination:\n  github: {owner: a}": {"repo required", "token required"},
+		`source: {dir: "${TENT_TEST_MISSING}"}`:                {"undefined environment variables: TENT_TEST_MISSING"},
+		"source: {dir: a}\nunknown: ignore":                    {`invalid policy "ignore"`},
+		"source: {dir: a}\nid_pattern: \"[a-\"":                {"id_pattern: error parsing regexp"},
+		"source: {dir: a}\nsoruce: {}":                         {"soruce"},
+	}
+	for input, msgs := range testCases {
+		_, err := Decode(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("%q: expected error", input)
+		}
+		for _, m := range msgs {
+			if !strings.Contains(err.Error(), m) {
+				t.Fatalf("%q: expected %q in %q", input, m, err)
+			}
+		}
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	c, err := Decode(strings.NewReader("source: {dir: ., prefix: [config], suffix: [_test.go]}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := c.NewSource(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for i, err := src.Next(); i != nil; i, err = src.Next() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, i.Name())
+	}
+	if len(names) != 1 || names[0] != "config_test.go" {
+		t.Fatalf("Expected %v, got %v", []string{"config_test.go"}, names)
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// Attachment is a generic file with no matching Component, the ID is the filename.
+type Attachment struct {
+	ID   string
+	Data []byte
+}
+
+// MarshalJSON implements the json.Marshaler interface, omitting the Data.
+func (a *Attachment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"ID":   a.ID,
+		"Size": len(a.Data),
+	})
+}
+
+// GetID implements the Component interface.
+func (a *Attachment) GetID() string { return a.ID }
+
+// SetID implements the Renamer interface.
+func (a *Attachment) SetID(id string) { a.ID = id }
+
+// Encode returns Item contents.
+func (a *Attachment) Encode() ([]byte, error) {
+	return a.Data, nil
+}
+
+// Order returns math.MaxFloat64, Attachments are shown last.
+func (*Attachment) Order() float64 { return math.MaxFloat64 }
+
+func (a Attachment) String() string {
+	return fmt.Sprintf("Attachment:%s Size:%v", a.ID, len(a.Data))
+}
+
+// Format implements the Component interface, Attachments match no file.
+func (*Attachment) Format() (string, []string) { return "", nil }
+
+// Decode returns a new Attachment with Item contents.
+func (a *Attachment) Decode(id string, r io.Reader) (Component, error) {
+	return a.decode(id, r)
+}
+
+func (*Attachment) decode(id string, r io.Reader) (*Attachment, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Attachment{ID: id, Data: data}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"sort"
 	"strings"
 
@@ -12,16 +13,33 @@ import (
 
 // Category is a branch node in the tree.
 type Category struct {
-	ID         string            `yaml:"-"`
-	Index      float64           `yaml:"index,omitempty"`
-	Meta       map[string]string `yaml:",inline"`
-	Sub        []Category        `yaml:"-"`
-	Components []Component       `yaml:"-"`
+	ID    string  `yaml:"-"`
+	Index float64 `yaml:"index,omitempty"`
+	// Sort is the ordering of the contents: "index" (default), "title" or
+	// "date" (with an optional Meta key, like "date:published"). A "-" prefix
+	// reverses it, ties are sorted by ID.
+	Sort string `yaml:"sort,omitempty"`
+	// Explicit lists IDs in order, they come before the other elements.
+	Explicit   []string    `yaml:"order,omitempty"`
+	Meta       Meta        `yaml:",inline"`
+	Sub        []Category  `yaml:"-"`
+	Components []Component `yaml:"-"`
+	// Schema is the one defined in the Category, if any.
+	Schema *Schema `yaml:"-"`
+
+	// src is the decoded YAML, Encode patches it.
+	src []byte
 }
 
 // GetID implements the Component interface.
 func (c *Category) GetID() string { return c.ID }
 
+// SetID implements the Renamer interface.
+func (c *Category) SetID(id string) { c.ID = id }
+
+// SetIndex implements the Indexer interface.
+func (c *Category) SetIndex(i float64) { c.Index = i }
+
 // Format implements the Component interface.
 func (c *Category) Format() (string, []string) { return "", nil }
 
@@ -34,25 +52,96 @@ func (c *Category) String() string {
 
 // Encode returns Item contents.
 func (c *Category) Encode() ([]byte, error) {
-	b := bytes.NewBuffer(nil)
-	if err := yaml.NewEncoder(b).Encode(c); err != nil {
-		return nil, err
-	}
-	return b.Bytes(), nil
+	return encodeYAML(c.src, c)
 }
 
 func (c *Category) sort() {
+	less := c.less()
 	sort.Slice(c.Sub, func(i, j int) bool {
-		return c.Sub[i].Index < c.Sub[j].Index
+		return less(&c.Sub[i], &c.Sub[j])
 	})
 	sort.Slice(c.Components, func(i, j int) bool {
-		return c.Components[i].Order() < c.Components[j].Order()
+		return less(c.Components[i], c.Components[j])
 	})
 	for i := range c.Sub {
 		c.Sub[i].sort()
 	}
 }
 
+// less returns the ordering function for the Category contents.
+func (c *Category) less() func(a, b Component) bool {
+	mode, desc, key := parseSort(c.Sort)
+	position := make(map[string]int, len(c.Explicit))
+	for i, id := range c.Explicit {
+		position[id] = i + 1
+	}
+	return func(a, b Component) bool {
+		if pa, pb := position[a.GetID()], position[b.GetID()]; pa != pb {
+			return pb == 0 || pa != 0 && pa < pb
+		}
+		var n int
+		switch mode {
+		case "title":
+			n = strings.Compare(strings.ToLower(metaOf(a).String("title")), strings.ToLower(metaOf(b).String("title")))
+		case "date":
+			ta, oka := metaOf(a).Time(key)
+			tb, okb := metaOf(b).Time(key)
+			if oka != okb {
+				// missing dates come last
+				return oka
+			}
+			switch {
+			case ta.Before(tb):
+				n = -1
+			case tb.Before(ta):
+				n = 1
+			}
+		default:
+			switch oa, ob := a.Order(), b.Order(); {
+			case oa < ob:
+				n = -1
+			case ob < oa:
+				n = 1
+			}
+		}
+		if desc {
+			n = -n
+		}
+		if n != 0 {
+			return n < 0
+		}
+		return a.GetID() < b.GetID()
+	}
+}
+
+// parseSort splits a Sort value in its parts.
+func parseSort(s string) (mode string, desc bool, key string) {
+	if strings.HasPrefix(s, "-") {
+		s, desc = s[1:], true
+	}
+	mode, key = s, "date"
+	if i := strings.IndexByte(s, ':'); i != -1 {
+		mode, key = s[:i], s[i+1:]
+	}
+	return mode, desc, key
+}
+
+// checkSort verifies the Sort value.
+func checkSort(s string) error {
+	mode, _, key := parseSort(s)
+	switch mode {
+	case "", "index", "title":
+		if mode == s || "-"+mode == s {
+			return nil
+		}
+	case "date":
+		if key != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid sort %q", s)
+}
+
 // ensure follows the path to a leaf node, creating all needed ones.
 func (c *Category) ensure(path string) *Category {
 	if path == "" || path == "." {
@@ -78,8 +167,15 @@ func (c *Category) Decode(id string, r io.Reader) (Component, error) {
 }
 
 func (*Category) decode(id string, r io.Reader) (*Category, error) {
-	c := Category{ID: id}
-	if err := yaml.NewDecoder(r).Decode(&c); err != nil {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	c := Category{ID: id, src: src}
+	if err := yaml.NewDecoder(bytes.NewReader(src)).Decode(&c); err != nil {
+		return nil, yamlError(err, 0)
+	}
+	if err := checkSort(c.Sort); err != nil {
 		return nil, err
 	}
 	return &c, nil
@@ -6,7 +6,7 @@ import (
 )
 
 func TestCategory(t *testing.T) {
-	c1 := Category{ID: "a", Index: 7, Meta: map[string]string{"title": "hello"}}
+	c1 := Category{ID: "a", Index: 7, Meta: Meta{"title": "hello"}}
 	b, err := c1.Encode()
 	if err != nil {
 		t.Fatal(err)
@@ -26,3 +26,43 @@ func TestCategory(t *testing.T) {
 		t.Fatalf("Expected %v data, got %v", c1.Meta, c2.Meta)
 	}
 }
+
+func TestCategorySort(t *testing.T) {
+	segments := func() []Component {
+		return []Component{
+			&Segment{ID: "c", Index: 1, Meta: Meta{"title": "banana", "date": "2019-02-01", "published": "2018-01-01"}},
+			&Segment{ID: "a", Index: 2, Meta: Meta{"title": "Apple", "date": "2019-03-01"}},
+			&Segment{ID: "b", Index: 1, Meta: Meta{"title": "cherry", "published": "2019-01-01"}},
+			&Segment{ID: "d", Index: 3, Meta: Meta{"title": "apple", "date": "2019-01-01"}},
+		}
+	}
+	for _, tc := range []struct {
+		sort     string
+		explicit []string
+		exp      string
+	}{
+		{"", nil, "bcad"},
+		{"-index", nil, "dabc"},
+		{"title", nil, "adcb"},
+		{"date", nil, "dcab"},
+		{"-date", nil, "acdb"},
+		{"-date:published", nil, "bcad"},
+		{"title", []string{"b", "x", "c"}, "bcad"},
+	} {
+		c := Category{Sort: tc.sort, Explicit: tc.explicit, Components: segments()}
+		c.sort()
+		var got string
+		for _, cmp := range c.Components {
+			got += cmp.GetID()
+		}
+		if got != tc.exp {
+			t.Fatalf("%q %v: expected %s, got %s", tc.sort, tc.explicit, tc.exp, got)
+		}
+	}
+	for _, s := range []string{"index", "-title", "date:published", "name", "date:", "title:x"} {
+		_, err := (*Category).decode(nil, "a", bytes.NewBufferString("sort: "+s+"\n"))
+		if valid := s != "name" && s != "date:" && s != "title:x"; valid != (err == nil) {
+			t.Fatalf("%q: unexpected error %v", s, err)
+		}
+	}
+}
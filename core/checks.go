@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 
 	"gopkg.in/yaml.v2"
 )
@@ -14,11 +15,20 @@ type Checks struct {
 	Index float64           `yaml:"index,omitempty"`
 	Meta  map[string]string `yaml:",inline"`
 	List  []Check           `yaml:"list,omitempty"`
+
+	// src is the decoded YAML, Encode patches it.
+	src []byte
 }
 
 // GetID implements the Component interface.
 func (c *Checks) GetID() string { return c.ID }
 
+// SetID implements the Renamer interface.
+func (c *Checks) SetID(id string) { c.ID = id }
+
+// SetIndex implements the Indexer interface.
+func (c *Checks) SetIndex(i float64) { c.Index = i }
+
 // Order implements the Component interface.
 func (c *Checks) Order() float64 { return c.Index }
 
@@ -28,11 +38,7 @@ func (c Checks) String() string {
 
 // Encode returns Item contents.
 func (c *Checks) Encode() ([]byte, error) {
-	b := bytes.NewBuffer(nil)
-	if err := yaml.NewEncoder(b).Encode(c); err != nil {
-		return nil, err
-	}
-	return b.Bytes(), nil
+	return encodeYAML(c.src, c)
 }
 
 // Format implements the Decoder interface.
@@ -43,10 +49,14 @@ func (c *Checks) Decode(id string, r io.Reader) (Component, error) {
 	return c.decode(id, r)
 }
 func (*Checks) decode(id string, r io.Reader) (*Checks, error) {
-	c := Checks{ID: id}
-	if err := yaml.NewDecoder(r).Decode(&c); err != nil {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
 		return nil, err
 	}
+	c := Checks{ID: id, src: src}
+	if err := yaml.NewDecoder(bytes.NewReader(src)).Decode(&c); err != nil {
+		return nil, yamlError(err, 0)
+	}
 	return &c, nil
 }
 
@@ -0,0 +1,456 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Condition is an expression on Form answers, used by show_if and
+// next_screen_if. It compares item names with literals:
+//
+//	age >= 18 and (country == "IT" or country in ["FR", "ES"])
+//
+// Operators are ==, !=, <, <=, >, >=, in, and, or, not. Literals are strings
+// in single or double quotes, numbers, true and false. A name alone is true
+// if the answer is not empty or false. With a list answer, like checkbox
+// options, == and in are true if any element matches. An empty Condition is
+// always true.
+type Condition string
+
+// Check verifies the Condition syntax.
+func (c Condition) Check() error {
+	_, err := c.parse()
+	return err
+}
+
+// Eval returns the value of the Condition for the answers.
+func (c Condition) Eval(answers map[string]interface{}) (bool, error) {
+	e, err := c.parse()
+	if err != nil {
+		return false, err
+	}
+	return truth(e.eval(answers)), nil
+}
+
+func (c Condition) parse() (expr, error) {
+	if strings.TrimSpace(string(c)) == "" {
+		return literal{true}, nil
+	}
+	tokens, err := lex(string(c))
+	if err != nil {
+		return nil, fmt.Errorf("%q: %s", c, err)
+	}
+	p := condParser{tokens: tokens}
+	e, err := p.or()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%q: %s", c, err)
+	}
+	return e, nil
+}
+
+// expr is a parsed Condition.
+type expr interface {
+	eval(answers map[string]interface{}) interface{}
+}
+
+type (
+	literal struct{ v interface{} }
+	name    string
+	list    []expr
+	notExpr struct{ e expr }
+	logic   struct {
+		op   string
+		l, r expr
+	}
+	compare struct {
+		op   string
+		l, r expr
+	}
+)
+
+func (l literal) eval(map[string]interface{}) interface{}   { return l.v }
+func (n name) eval(a map[string]interface{}) interface{}    { return a[string(n)] }
+func (n notExpr) eval(a map[string]interface{}) interface{} { return !truth(n.e.eval(a)) }
+
+func (l list) eval(a map[string]interface{}) interface{} {
+	v := make([]interface{}, len(l))
+	for i := range l {
+		v[i] = l[i].eval(a)
+	}
+	return v
+}
+
+func (l logic) eval(a map[string]interface{}) interface{} {
+	if l.op == "and" {
+		return truth(l.l.eval(a)) && truth(l.r.eval(a))
+	}
+	return truth(l.l.eval(a)) || truth(l.r.eval(a))
+}
+
+func (c compare) eval(a map[string]interface{}) interface{} {
+	l, r := c.l.eval(a), c.r.eval(a)
+	switch c.op {
+	case "==":
+		return equal(l, r)
+	case "!=":
+		return !equal(l, r)
+	case "in":
+		for _, v := range r.([]interface{}) {
+			if equal(l, v) {
+				return true
+			}
+		}
+		return false
+	}
+	n, ok := order(l, r)
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	case ">":
+		return n > 0
+	default:
+		return n >= 0
+	}
+}
+
+// truth converts a value to bool.
+func truth(v interface{}) bool {
+	if b, ok := boolean(v); ok {
+		return b
+	}
+	return !isEmpty(v)
+}
+
+// equal compares an answer to a value, a list answer matches any element.
+func equal(a, b interface{}) bool {
+	switch a.(type) {
+	case []interface{}, []string:
+		l, _ := answerList(a)
+		for _, v := range l {
+			if equal(v, b) {
+				return true
+			}
+		}
+		return false
+	}
+	if a == nil {
+		a = ""
+	}
+	if b == nil {
+		b = ""
+	}
+	if na, ok := number(a); ok {
+		if nb, ok := number(b); ok {
+			return na == nb
+		}
+	}
+	if _, ok := b.(bool); ok {
+		ba, ok := boolean(a)
+		return ok && ba == b
+	}
+	if _, ok := a.(bool); ok {
+		bb, ok := boolean(b)
+		return ok && bb == a
+	}
+	sa, okA := scalar(a)
+	sb, okB := scalar(b)
+	return okA && okB && sa == sb
+}
+
+// order compares two numbers or strings, ok is false for other values.
+func order(a, b interface{}) (n int, ok bool) {
+	if na, ok := number(a); ok {
+		if nb, ok := number(b); ok {
+			switch {
+			case na < nb:
+				return -1, true
+			case na > nb:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if !okA || !okB {
+		return 0, false
+	}
+	return strings.Compare(sa, sb), true
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokName
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end"
+	}
+	return strconv.Quote(t.text)
+}
+
+// lex splits a Condition into tokens, and, or, not, in, true and false are
+// operators.
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(s) && s[j] != s[i] {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			text, err := unquote(s[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d", i)
+			}
+			tokens = append(tokens, token{tokString, text})
+			i = j + 1
+		case c == '-' || unicode.IsDigit(c):
+			j := i + size
+			for j < len(s) && (s[j] == '.' || '0' <= s[j] && s[j] <= '9') {
+				j++
+			}
+			if _, err := strconv.ParseFloat(s[i:j], 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q", s[i:j])
+			}
+			tokens = append(tokens, token{tokNumber, s[i:j]})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i + size
+			for j < len(s) {
+				r, n := utf8.DecodeRuneInString(s[j:])
+				if !isNameRune(r) {
+					break
+				}
+				j += n
+			}
+			switch text := s[i:j]; text {
+			case "and", "or", "not", "in", "true", "false":
+				tokens = append(tokens, token{tokOp, text})
+			default:
+				tokens = append(tokens, token{tokName, text})
+			}
+			i = j
+		default:
+			op := s[i : i+size]
+			if i+1 < len(s) && s[i+1] == '=' && strings.ContainsRune("=!<>", c) {
+				op = s[i : i+2]
+			}
+			switch op {
+			case "==", "!=", "<", "<=", ">", ">=", "(", ")", "[", "]", ",":
+			default:
+				return nil, fmt.Errorf("unexpected %q at %d", op, i)
+			}
+			tokens = append(tokens, token{tokOp, op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// isNameRune tells if r can be part of an item name.
+func isNameRune(r rune) bool {
+	return r == '_' || r == '-' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// unquote returns the value of a quoted string. Single quoted strings have
+// the same escapes of double quoted ones, plus \'.
+func unquote(q string) (string, error) {
+	if q[0] == '\'' {
+		var b strings.Builder
+		b.WriteByte('"')
+		for i := 1; i < len(q)-1; i++ {
+			switch c := q[i]; {
+			case c == '\\' && q[i+1] == '\'':
+				b.WriteByte('\'')
+				i++
+			case c == '\\':
+				b.WriteString(q[i : i+2])
+				i++
+			case c == '"':
+				b.WriteString(`\"`)
+			default:
+				b.WriteByte(c)
+			}
+		}
+		b.WriteByte('"')
+		q = b.String()
+	}
+	return strconv.Unquote(q)
+}
+
+// condParser is a recursive descent parser:
+//
+//	or      = and { "or" and }
+//	and     = not { "and" not }
+//	not     = "not" not | compare
+//	compare = operand [ op operand | "in" list ]
+//	operand = name | literal | "(" or ")"
+type condParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *condParser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokEOF}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *condParser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// accept consumes the operator op, if it's next.
+func (p *condParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *condParser) expect(op string) error {
+	if !p.accept(op) {
+		return fmt.Errorf("expected %q, got %s", op, p.peek())
+	}
+	return nil
+}
+
+func (p *condParser) or() (expr, error) {
+	return p.binary("or", p.and)
+}
+
+func (p *condParser) and() (expr, error) {
+	return p.binary("and", p.not)
+}
+
+func (p *condParser) binary(op string, operand func() (expr, error)) (expr, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(op) {
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l = logic{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *condParser) not() (expr, error) {
+	if p.accept("not") {
+		e, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{e}, nil
+	}
+	return p.compare()
+}
+
+func (p *condParser) compare() (expr, error) {
+	l, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("in") {
+		r, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+		return compare{op: "in", l: l, r: r}, nil
+	}
+	for _, op := range []string{"==", "!=", "<=", "<", ">=", ">"} {
+		if p.accept(op) {
+			r, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return compare{op: op, l: l, r: r}, nil
+		}
+	}
+	return l, nil
+}
+
+func (p *condParser) list() (expr, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var l list
+	for !p.accept("]") {
+		if len(l) != 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		e, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, e)
+	}
+	return l, nil
+}
+
+func (p *condParser) operand() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokName:
+		return name(t.text), nil
+	case tokString:
+		return literal{t.text}, nil
+	case tokNumber:
+		n, _ := strconv.ParseFloat(t.text, 64)
+		return literal{n}, nil
+	case tokOp:
+		switch t.text {
+		case "true", "false":
+			return literal{t.text == "true"}, nil
+		case "(":
+			e, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
@@ -0,0 +1,59 @@
+package core
+
+import "testing"
+
+func TestCondition(t *testing.T) {
+	answers := map[string]interface{}{
+		"age":     "42",
+		"country": "IT",
+		"agree":   true,
+		"off":     "false",
+		"tags":    []interface{}{"a", "b"},
+		"day":     "2019-05-01",
+		"città":   "Roma",
+		"name":    "O'Brien",
+		"dir":     `a\b`,
+	}
+	for c, exp := range map[Condition]bool{
+		"":                                   true,
+		"age":                                true,
+		"missing":                            false,
+		"off":                                false,
+		"not off":                            true,
+		"age >= 18":                          true,
+		"age < 18":                           false,
+		"age == 42":                          true,
+		`country == "IT"`:                    true,
+		`country != 'IT'`:                    false,
+		`country in ["FR", "ES"]`:            false,
+		"agree == true":                      true,
+		`tags == "b"`:                        true,
+		`tags in ["c", "a"]`:                 true,
+		`day > "2019-01-01"`:                 true,
+		`missing == ""`:                      true,
+		`missing < 3`:                        false,
+		`age > 50 or agree and tags`:         true,
+		`(age > 50 or agree) and off`:        false,
+		`not (age > 50) and country == "IT"`: true,
+		`città == "Roma"`:                    true,
+		`città in ['Milano', "Roma"]`:        true,
+		`name == 'O\'Brien'`:                 true,
+		`name == "O'Brien"`:                  true,
+		`dir == 'a\\b'`:                      true,
+		`dir == "a\\b"`:                      true,
+		`"é" == 'é'`:                         true,
+	} {
+		got, err := c.Eval(answers)
+		if err != nil {
+			t.Fatalf("%q: %s", c, err)
+		}
+		if got != exp {
+			t.Fatalf("%q: expected %v, got %v", c, exp, got)
+		}
+	}
+	for _, c := range []Condition{"age >", "(age", "a == 'b", "a = 1", "a in b", "a b", "1.2.3"} {
+		if err := c.Check(); err == nil {
+			t.Fatalf("%q: expected error", c)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeType is the kind of a Change.
+type ChangeType int
+
+// Available ChangeTypes.
+const (
+	Added ChangeType = iota
+	Removed
+	Moved
+	Modified
+)
+
+func (c ChangeType) String() string {
+	switch c {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Moved:
+		return "moved"
+	case Modified:
+		return "modified"
+	default:
+		return fmt.Sprintf("ChangeType(%d)", int(c))
+	}
+}
+
+// Change is a difference between two trees.
+type Change struct {
+	Type ChangeType
+	// Kind is the Component type (example: Segment).
+	Kind string
+	// Path is the one in the new tree, the old one for Removed.
+	Path string
+	// From is the old path for Moved.
+	From string
+	// Fields are the changes for Modified, and for Moved if any.
+	Fields []FieldChange
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s", c.Type, c.Kind, c.Path)
+	if c.Type == Moved {
+		s = fmt.Sprintf("%s %s %s -> %s", c.Type, c.Kind, c.From, c.Path)
+	}
+	if len(c.Fields) == 0 {
+		return s
+	}
+	var f = make([]string, len(c.Fields))
+	for i := range c.Fields {
+		f[i] = c.Fields[i].Field
+	}
+	return fmt.Sprintf("%s (%s)", s, strings.Join(f, ", "))
+}
+
+// FieldChange is a modified field of a Component, Meta keys have a "meta." prefix.
+type FieldChange struct {
+	Field    string
+	Old, New interface{}
+}
+
+// Diff returns the changes from a to b, sorted by path.
+func Diff(a, b *Root) []Change {
+	return DiffCategories(a.Category, b.Category)
+}
+
+// DiffCategories returns the changes from a to b, sorted by path.
+func DiffCategories(a, b *Category) []Change {
+	var (
+		old, new = flatten(a), flatten(b)
+		changes  []Change
+		matched  = make(map[string]string) // new path -> old path
+	)
+	for p, n := range new {
+		if o, ok := old[p]; ok && typeName(o) == typeName(n) {
+			matched[p] = p
+		}
+	}
+	// moved categories carry along their contents
+	for _, m := range findMoves(old, new, matched, true) {
+		if _, done := matched[m.to]; done {
+			continue
+		}
+		changes = append(changes, m.change(old, new))
+		matched[m.to] = m.from
+		for p := range new {
+			if !strings.HasPrefix(p, m.to+"/") {
+				continue
+			}
+			from := m.from + strings.TrimPrefix(p, m.to)
+			if o, ok := old[from]; ok && typeName(o) == typeName(new[p]) {
+				if _, done := matched[p]; !done {
+					matched[p] = from
+				}
+			}
+		}
+	}
+	for _, m := range findMoves(old, new, matched, false) {
+		changes = append(changes, m.change(old, new))
+		matched[m.to] = m.from
+	}
+
+	used := make(map[string]bool, len(matched))
+	for to, from := range matched {
+		used[from] = true
+		if fields := diffFields(old[from], new[to]); len(fields) != 0 && !isMoveRoot(changes, to) {
+			changes = append(changes, Change{Type: Modified, Kind: typeName(new[to]), Path: to, Fields: fields})
+		}
+	}
+	for p, n := range new {
+		if _, ok := matched[p]; !ok {
+			changes = append(changes, Change{Type: Added, Kind: typeName(n), Path: p})
+		}
+	}
+	for p, o := range old {
+		if !used[p] {
+			changes = append(changes, Change{Type: Removed, Kind: typeName(o), Path: p})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Type < changes[j].Type
+	})
+	return changes
+}
+
+// isMoveRoot tells if the path is the destination of a Moved change.
+func isMoveRoot(changes []Change, p string) bool {
+	for _, c := range changes {
+		if c.Type == Moved && c.Path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten returns all the elements in the tree by path.
+func flatten(c *Category) map[string]Component {
+	m := make(map[string]Component)
+	c.Walk(func(p string, _ []*Category, cmp Component) error {
+		m[p] = cmp
+		return nil
+	})
+	return m
+}
+
+type move struct{ from, to string }
+
+func (m move) change(old, new map[string]Component) Change {
+	return Change{
+		Type:   Moved,
+		Kind:   typeName(new[m.to]),
+		Path:   m.to,
+		From:   m.from,
+		Fields: diffFields(old[m.from], new[m.to]),
+	}
+}
+
+// findMoves matches unmatched elements with the same type and ID, when there
+// is only one candidate on each side.
+func findMoves(old, new map[string]Component, matched map[string]string, categories bool) []move {
+	used := make(map[string]bool, len(matched))
+	for _, from := range matched {
+		used[from] = true
+	}
+	key := func(p string, cmp Component) string {
+		return typeName(cmp) + ":" + path.Base(p)
+	}
+	var removed, added = make(map[string][]string), make(map[string][]string)
+	for p, o := range old {
+		if _, isCat := o.(*Category); isCat == categories && !used[p] {
+			removed[key(p, o)] = append(removed[key(p, o)], p)
+		}
+	}
+	for p, n := range new {
+		if _, isCat := n.(*Category); isCat == categories {
+			if _, ok := matched[p]; !ok {
+				added[key(p, n)] = append(added[key(p, n)], p)
+			}
+		}
+	}
+	var moves []move
+	for k, to := range added {
+		if from := removed[k]; len(from) == 1 && len(to) == 1 {
+			moves = append(moves, move{from: from[0], to: to[0]})
+		}
+	}
+	// outer categories first, so their contents are matched
+	sort.Slice(moves, func(i, j int) bool {
+		return strings.Count(moves[i].to, "/") < strings.Count(moves[j].to, "/")
+	})
+	return moves
+}
+
+// diffFields compares the fields of two Components.
+func diffFields(a, b Component) []FieldChange {
+	fa, fb := fieldsOf(a), fieldsOf(b)
+	var keys []string
+	for k := range fa {
+		keys = append(keys, k)
+	}
+	for k := range fb {
+		if _, ok := fa[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var changes []FieldChange
+	for _, k := range keys {
+		if !reflect.DeepEqual(fa[k], fb[k]) {
+			changes = append(changes, FieldChange{Field: k, Old: fa[k], New: fb[k]})
+		}
+	}
+	return changes
+}
+
+// fieldsOf returns the comparable fields of a Component.
+func fieldsOf(cmp Component) map[string]interface{} {
+	m := make(map[string]interface{})
+	addMeta := func(meta map[string]interface{}) {
+		for k, v := range meta {
+			m["meta."+k] = v
+		}
+	}
+	addStrings := func(meta map[string]string) {
+		for k, v := range meta {
+			m["meta."+k] = v
+		}
+	}
+	switch v := cmp.(type) {
+	case *Category:
+		m["index"] = v.Index
+		m["sort"] = v.Sort
+		m["order"] = v.Explicit
+		addMeta(v.Meta)
+	case *Segment:
+		m["index"] = v.Index
+		m["body"] = string(v.Body)
+		addMeta(v.Meta)
+	case *Checks:
+		m["index"] = v.Index
+		m["list"] = v.List
+		addStrings(v.Meta)
+	case *Form:
+		m["index"] = v.Index
+		m["screens"] = v.Screens
+		addStrings(v.Meta)
+	case *Picture:
+		m["data"] = v.Data
+	case *Attachment:
+		m["data"] = v.Data
+	default:
+		b, err := cmp.Encode()
+		if err != nil {
+			m["contents"] = err.Error()
+		} else {
+			m["contents"] = string(b)
+		}
+	}
+	return m
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := &Category{ID: "root", Sub: []Category{
+		{ID: "guides", Meta: Meta{"title": "Guides"}, Sub: []Category{
+			{ID: "setup", Components: []Component{
+				&Segment{ID: "intro", Index: 1, Body: []byte("a")},
+				&Segment{ID: "install", Index: 2},
+			}},
+		}},
+		{ID: "news", Components: []Component{
+			&Segment{ID: "first", Meta: Meta{"title": "First"}},
+			&Picture{ID: "old.png"},
+		}},
+	}}
+	b := &Category{ID: "root", Sub: []Category{
+		{ID: "guides", Meta: Meta{"title": "All guides"}},
+		{ID: "docs", Sub: []Category{
+			{ID: "setup", Components: []Component{
+				&Segment{ID: "intro", Index: 1, Body: []byte("b")},
+				&Segment{ID: "install", Index: 2},
+			}},
+		}},
+		{ID: "news", Components: []Component{
+			&Segment{ID: "first", Index: 3, Meta: Meta{"title": "First"}},
+			&Segment{ID: "second"},
+		}},
+	}}
+	var got []string
+	for _, c := range DiffCategories(a, b) {
+		got = append(got, c.String())
+	}
+	exp := []string{
+		"added Category docs",
+		"moved Category guides/setup -> docs/setup",
+		"modified Segment docs/setup/intro (body)",
+		"modified Category guides (meta.title)",
+		"modified Segment news/first (index)",
+		"removed Picture news/old.png",
+		"added Segment news/second",
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("Expected:\n%v\nGot:\n%v", exp, got)
+	}
+	if len(DiffCategories(a, a)) != 0 {
+		t.Fatal("Expected no changes")
+	}
+}
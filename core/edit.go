@@ -0,0 +1,573 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-tent/tent/item"
+)
+
+// ChangeSet lists the Items needed to persist an edit of the tree.
+type ChangeSet struct {
+	Create []item.Memory
+	Update []item.Memory
+	Delete []item.Memory
+}
+
+func (c ChangeSet) String() string {
+	var s []string
+	for _, v := range []struct {
+		name  string
+		items []item.Memory
+	}{{"create", c.Create}, {"update", c.Update}, {"delete", c.Delete}} {
+		for _, i := range v.items {
+			s = append(s, v.name+" "+i.ID)
+		}
+	}
+	return strings.Join(s, "\n")
+}
+
+// Delete removes the element at the given path.
+func (r *Root) Delete(p string) (ChangeSet, error) {
+	e, err := r.element(p)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	old, err := r.subtree(e.prefix, e.cmp)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	e.detach()
+	r.dropOrigins(old)
+	return ChangeSet{Delete: old}, nil
+}
+
+// Move moves the element at the given path into a Category, keeping its Index.
+func (r *Root) Move(p, category string) (ChangeSet, error) {
+	return r.relocate(p, category, "", nil)
+}
+
+// Rename changes the ID of the element at the given path.
+func (r *Root) Rename(p, id string) (ChangeSet, error) {
+	return r.relocate(p, path.Dir(cleanPath(p)), id, nil)
+}
+
+// MoveBefore moves the element at p before target, which can be in another Category.
+func (r *Root) MoveBefore(p, target string) (ChangeSet, error) {
+	return r.reorder(p, target, false)
+}
+
+// MoveAfter moves the element at p after target, which can be in another Category.
+func (r *Root) MoveAfter(p, target string) (ChangeSet, error) {
+	return r.reorder(p, target, true)
+}
+
+// InsertBefore adds a new Component or Category before the element at target.
+func (r *Root) InsertBefore(target string, cmp Component) (ChangeSet, error) {
+	return r.insert(target, cmp, false)
+}
+
+// InsertAfter adds a new Component or Category after the element at target.
+func (r *Root) InsertAfter(target string, cmp Component) (ChangeSet, error) {
+	return r.insert(target, cmp, true)
+}
+
+func (r *Root) insert(target string, cmp Component, after bool) (ChangeSet, error) {
+	t, err := r.element(target)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	if err := r.canAdd(t.parent, t.prefix, cmp); err != nil {
+		return ChangeSet{}, err
+	}
+	changed, err := t.insert(cmp, after)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	var cs ChangeSet
+	if cs.Create, err = r.subtree(t.prefix, t.parent.find(cmp)); err != nil {
+		return ChangeSet{}, err
+	}
+	if err := r.updated(&cs, t.prefix, changed); err != nil {
+		return ChangeSet{}, err
+	}
+	return cs, nil
+}
+
+func (r *Root) reorder(p, target string, after bool) (ChangeSet, error) {
+	if path.Dir(cleanPath(p)) != path.Dir(cleanPath(target)) {
+		t, err := r.element(target)
+		if err != nil {
+			return ChangeSet{}, err
+		}
+		e, err := r.element(p)
+		if err != nil {
+			return ChangeSet{}, err
+		}
+		if e.isCategory() != t.isCategory() {
+			return ChangeSet{}, fmt.Errorf("%s and %s are not of the same kind", p, target)
+		}
+		return r.relocate(p, path.Dir(cleanPath(target)), "", &placement{target: target, after: after})
+	}
+	e, err := r.element(p)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	t, err := r.element(target)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	if t.cmp == e.cmp {
+		return ChangeSet{}, nil
+	}
+	cmp := e.detach()
+	t, _ = r.element(target)
+	changed, err := t.insert(cmp, after)
+	if err != nil {
+		t.parent.add(cmp)
+		t.parent.sort()
+		return ChangeSet{}, err
+	}
+	changed = append([]Component{t.parent.find(cmp)}, changed...)
+	var cs ChangeSet
+	if err := r.updated(&cs, t.prefix, changed); err != nil {
+		return ChangeSet{}, err
+	}
+	return cs, nil
+}
+
+// placement is the position for a relocated element.
+type placement struct {
+	target string
+	after  bool
+}
+
+// relocate moves the element at p to category, with a new id if not empty,
+// next to a target if pos is not nil.
+func (r *Root) relocate(p, category, id string, pos *placement) (ChangeSet, error) {
+	e, err := r.element(p)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	category = cleanPath(category)
+	if e.isCategory() && (category == e.path || strings.HasPrefix(category+"/", e.path+"/")) {
+		return ChangeSet{}, fmt.Errorf("cannot move %s into itself", p)
+	}
+	dst, ok := r.Find(category + "/")
+	if _, isCat := dst.(*Category); !ok || !isCat {
+		return ChangeSet{}, fmt.Errorf("category %q not found", category)
+	}
+	if id == "" {
+		id = e.cmp.GetID()
+	}
+	if path.Join(category, id) == e.path {
+		return ChangeSet{}, nil
+	}
+	if _, ok := e.cmp.(Renamer); !ok && id != e.cmp.GetID() {
+		return ChangeSet{}, fmt.Errorf("%s cannot be renamed", p)
+	}
+	old, err := r.subtree(e.prefix, e.cmp)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	oldID, oldName := e.cmp.GetID(), itemName(e.prefix, e.cmp)
+	cmp := e.detach()
+	renamer, _ := cmp.(Renamer)
+	restore := func() {
+		if renamer != nil {
+			renamer.SetID(oldID)
+		}
+		e.parent = r.category(e.prefix)
+		e.parent.add(cmp)
+		e.parent.sort()
+	}
+	if renamer != nil {
+		renamer.SetID(id)
+	}
+	prefix := splitPath(category)
+	target := r.category(prefix)
+	if err := r.canAdd(target, prefix, cmp); err != nil {
+		restore()
+		return ChangeSet{}, err
+	}
+
+	var changed []Component
+	if pos != nil {
+		t, err := r.element(pos.target)
+		if err == nil {
+			changed, err = t.insert(cmp, pos.after)
+		}
+		if err != nil {
+			restore()
+			return ChangeSet{}, err
+		}
+	} else {
+		target.add(cmp)
+		target.sort()
+	}
+	cmp = target.find(cmp)
+
+	var cs = ChangeSet{Delete: old}
+	r.moveOrigins(old, oldName, e.path, itemName(prefix, cmp), path.Join(category, id))
+	if cs.Create, err = r.subtree(prefix, cmp); err != nil {
+		return ChangeSet{}, err
+	}
+	if err := r.updated(&cs, prefix, changed); err != nil {
+		return ChangeSet{}, err
+	}
+	return cs, nil
+}
+
+// canAdd verifies that cmp can be added to the Category.
+func (r *Root) canAdd(c *Category, prefix []string, cmp Component) error {
+	id := cmp.GetID()
+	if id == "" {
+		return errors.New("empty ID")
+	}
+	if p := r.opts.IDPattern; p != nil && !p.MatchString(id) {
+		return fmt.Errorf("invalid ID %q, must match %s", id, p)
+	}
+	if _, ok := cmp.(*Category); ok {
+		for i := range c.Sub {
+			if strings.EqualFold(c.Sub[i].ID, id) {
+				return fmt.Errorf("ID %q already used in %q", id, path.Join(prefix...))
+			}
+		}
+		return nil
+	}
+	for _, v := range c.Components {
+		if strings.EqualFold(v.GetID(), id) {
+			return fmt.Errorf("ID %q already used in %q", id, path.Join(prefix...))
+		}
+	}
+	if _, ok := cmp.(*Attachment); ok {
+		return nil
+	}
+	_, file := path.Split(itemName(nil, cmp))
+	for _, d := range r.decoders {
+		if r.matchDecoder(d, file) != "" && typeName(d) == typeName(cmp) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no decoder for %s", file)
+}
+
+// subtree returns the Items of the element and its contents.
+func (r *Root) subtree(prefix []string, cmp Component) ([]item.Memory, error) {
+	var items []item.Memory
+	cat, ok := cmp.(*Category)
+	if !ok {
+		if err := r.encodeItem(prefix, cmp, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+	p := append(prefix[:len(prefix):len(prefix)], cat.ID)
+	if r.hasFile(p, cat) {
+		if err := r.encodeItem(p, cat, &items); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.encode(p, cat, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// updated adds the Items of the changed elements to the ChangeSet, that can
+// include the Category at prefix. Categories are created if they had no file.
+func (r *Root) updated(cs *ChangeSet, prefix []string, list []Component) error {
+	for _, cmp := range list {
+		p := prefix
+		if cat, ok := cmp.(*Category); ok && cat != r.category(prefix) {
+			p = append(prefix[:len(prefix):len(prefix)], cat.ID)
+		}
+		var items []item.Memory
+		if err := r.encodeItem(p, cmp, &items); err != nil {
+			return err
+		}
+		if _, ok := r.origins[items[0].ID]; !ok {
+			if _, isCat := cmp.(*Category); isCat {
+				cs.Create = append(cs.Create, items[0])
+				continue
+			}
+		}
+		cs.Update = append(cs.Update, items[0])
+	}
+	return nil
+}
+
+// dropOrigins removes the origins of the Items.
+func (r *Root) dropOrigins(items []item.Memory) {
+	for _, i := range items {
+		delete(r.origins, i.ID)
+	}
+}
+
+// moveOrigins moves the origins of the old Items to their new path: the
+// Component named oldName or the contents of the Category in from.
+func (r *Root) moveOrigins(old []item.Memory, oldName, from, newName, to string) {
+	for _, i := range old {
+		o, ok := r.origins[i.ID]
+		if !ok {
+			continue
+		}
+		delete(r.origins, i.ID)
+		switch {
+		case i.ID == oldName:
+			r.origins[newName] = o
+		case strings.HasPrefix(i.ID, from+"/"):
+			r.origins[to+strings.TrimPrefix(i.ID, from)] = o
+		}
+	}
+}
+
+// category returns the Category at the given path, it must exist.
+func (r *Root) category(prefix []string) *Category {
+	cmp, _ := r.Find(path.Join(prefix...) + "/")
+	return cmp.(*Category)
+}
+
+// element is a located element of the tree.
+type element struct {
+	path   string
+	prefix []string
+	parent *Category
+	cmp    Component
+}
+
+func (r *Root) element(p string) (*element, error) {
+	parents, cmp, ok := r.locate(p)
+	p = cleanPath(p)
+	if !ok {
+		return nil, fmt.Errorf("%q not found", p)
+	}
+	if len(parents) == 0 {
+		return nil, errors.New("root cannot be edited")
+	}
+	return &element{
+		path:   p,
+		prefix: splitPath(path.Dir(p)),
+		parent: parents[len(parents)-1],
+		cmp:    cmp,
+	}, nil
+}
+
+func (e *element) isCategory() bool {
+	_, ok := e.cmp.(*Category)
+	return ok
+}
+
+// detach removes the element from its parent, returning it.
+func (e *element) detach() Component {
+	if cat, ok := e.cmp.(*Category); ok {
+		for i := range e.parent.Sub {
+			if &e.parent.Sub[i] == cat {
+				c := e.parent.Sub[i]
+				e.parent.Sub = append(e.parent.Sub[:i], e.parent.Sub[i+1:]...)
+				e.cmp = &c
+				return &c
+			}
+		}
+	}
+	for i := range e.parent.Components {
+		if e.parent.Components[i] == e.cmp {
+			e.parent.Components = append(e.parent.Components[:i], e.parent.Components[i+1:]...)
+			break
+		}
+	}
+	return e.cmp
+}
+
+// insert adds cmp next to the element, returning the siblings whose Index
+// changed and the parent if its order changed. Next to elements in the order
+// list, cmp is added to the list. Otherwise the Index is set in the direction
+// of the sort, that must be by index.
+func (e *element) insert(cmp Component, after bool) ([]Component, error) {
+	cat, isCat := cmp.(*Category)
+	if isCat != e.isCategory() {
+		return nil, fmt.Errorf("%s: cannot insert %s next to %s", e.path, typeName(cmp), typeName(e.cmp))
+	}
+	var (
+		p             = e.parent
+		id            = cmp.GetID()
+		order         []string
+		pos           = -1
+		mode, desc, _ = parseSort(p.Sort)
+	)
+	for _, v := range p.Explicit {
+		if v == id {
+			continue
+		}
+		if v == e.cmp.GetID() {
+			pos = len(order)
+		}
+		order = append(order, v)
+	}
+	if pos == -1 && mode != "" && mode != "index" {
+		return nil, fmt.Errorf("%s: cannot place elements in a category sorted by %s", e.path, mode)
+	}
+	var seq []Component
+	if isCat {
+		var k int
+		for i := range p.Sub {
+			if p.Sub[i].ID == e.cmp.GetID() {
+				k = i
+			}
+		}
+		if after {
+			k++
+		}
+		p.Sub = append(p.Sub, Category{})
+		copy(p.Sub[k+1:], p.Sub[k:])
+		p.Sub[k] = *cat
+		for i := range p.Sub {
+			seq = append(seq, &p.Sub[i])
+		}
+		cmp = &p.Sub[k]
+	} else {
+		var k int
+		for i := range p.Components {
+			if p.Components[i] == e.cmp {
+				k = i
+			}
+		}
+		if after {
+			k++
+		}
+		p.Components = append(p.Components, nil)
+		copy(p.Components[k+1:], p.Components[k:])
+		p.Components[k] = cmp
+		seq = p.Components
+	}
+	var changed []Component
+	if pos != -1 || len(order) != len(p.Explicit) {
+		if pos != -1 {
+			if after {
+				pos++
+			}
+			order = append(order[:pos], append([]string{id}, order[pos:]...)...)
+		}
+		p.Explicit = order
+		changed = append(changed, p)
+	}
+	if pos != -1 {
+		return changed, nil
+	}
+	// the listed elements come first, the Index orders the others
+	var (
+		listed   = make(map[string]bool, len(p.Explicit))
+		unlisted []Component
+		k        int
+	)
+	for _, v := range p.Explicit {
+		listed[v] = true
+	}
+	for _, c := range seq {
+		if listed[c.GetID()] {
+			continue
+		}
+		if c == cmp {
+			k = len(unlisted)
+		}
+		unlisted = append(unlisted, c)
+	}
+	return append(changed, reindex(unlisted, k, desc)...), nil
+}
+
+// reindex sets the Index of seq[k] between its siblings, changing the
+// following ones only if there is no room. If desc is true, seq is sorted by
+// descending Index. Returns the changed siblings.
+func reindex(seq []Component, k int, desc bool) []Component {
+	idx, ok := seq[k].(Indexer)
+	if !ok {
+		return nil
+	}
+	sign := 1.0
+	if desc {
+		sign = -1
+	}
+	var (
+		prev, next       float64
+		hasPrev, hasNext bool
+	)
+	for i := k - 1; i >= 0 && !hasPrev; i-- {
+		if _, ok := seq[i].(Indexer); ok {
+			prev, hasPrev = sign*seq[i].Order(), true
+		}
+	}
+	for i := k + 1; i < len(seq) && !hasNext; i++ {
+		if _, ok := seq[i].(Indexer); ok {
+			next, hasNext = sign*seq[i].Order(), true
+		}
+	}
+	switch mid := prev + (next-prev)/2; {
+	case !hasPrev && !hasNext:
+		idx.SetIndex(1)
+	case !hasPrev:
+		idx.SetIndex(sign * (next - 1))
+	case !hasNext:
+		idx.SetIndex(sign * (prev + 1))
+	case mid > prev && mid < next:
+		idx.SetIndex(sign * mid)
+	default:
+		idx.SetIndex(sign * (prev + 1))
+		var changed []Component
+		last := prev + 1
+		for _, c := range seq[k+1:] {
+			s, ok := c.(Indexer)
+			if !ok {
+				continue
+			}
+			if sign*c.Order() > last {
+				break
+			}
+			last++
+			s.SetIndex(sign * last)
+			changed = append(changed, c)
+		}
+		return changed
+	}
+	return nil
+}
+
+// add appends a Component or a Category.
+func (c *Category) add(cmp Component) {
+	if cat, ok := cmp.(*Category); ok {
+		c.Sub = append(c.Sub, *cat)
+		return
+	}
+	c.Components = append(c.Components, cmp)
+}
+
+// find returns the element in c with the same ID and kind of cmp.
+func (c *Category) find(cmp Component) Component {
+	if _, ok := cmp.(*Category); ok {
+		for i := range c.Sub {
+			if c.Sub[i].ID == cmp.GetID() {
+				return &c.Sub[i]
+			}
+		}
+		return nil
+	}
+	for _, v := range c.Components {
+		if v.GetID() == cmp.GetID() {
+			return v
+		}
+	}
+	return nil
+}
+
+func cleanPath(p string) string {
+	return strings.Trim(path.Clean("/"+p), "/")
+}
+
+func splitPath(p string) []string {
+	p = cleanPath(p)
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-tent/tent/item"
+	"github.com/go-tent/tent/source"
+)
+
+func editRoot(t *testing.T) *Root {
+	items := []item.Memory{
+		{ID: "a/.category.yml", Contents: []byte("index: 1\n")},
+		{ID: "a/s_one.md", Contents: []byte("---\nindex: 1\n---\none")},
+		{ID: "a/s_two.md", Contents: []byte("---\nindex: 2\n---\ntwo")},
+		{ID: "a/s_three.md", Contents: []byte("---\nindex: 2\n---\nthree")},
+		{ID: "a/s_four.md", Contents: []byte("---\nindex:   5\n---\nfour")},
+		{ID: "b/.category.yml", Contents: []byte("index: 2\n")},
+		{ID: "b/c/s_x.md", Contents: []byte("---\nindex: 1\n---\nx")},
+	}
+	r, err := NewRoot(Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Decode(&source.Memory{Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func checkChangeSet(t *testing.T, cs ChangeSet, create, update, delete []string) {
+	t.Helper()
+	for _, v := range []struct {
+		name     string
+		got      []item.Memory
+		expected []string
+	}{{"create", cs.Create, create}, {"update", cs.Update, update}, {"delete", cs.Delete, delete}} {
+		if len(v.got) != len(v.expected) {
+			t.Fatalf("Expected %s %v, got:\n%s", v.name, v.expected, cs)
+		}
+		for i := range v.got {
+			if v.got[i].ID != v.expected[i] {
+				t.Fatalf("Expected %s %v, got:\n%s", v.name, v.expected, cs)
+			}
+		}
+	}
+}
+
+func TestEditInsert(t *testing.T) {
+	r := editRoot(t)
+	cs, err := r.InsertAfter("a/one", &Segment{ID: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, []string{"a/s_new.md"}, nil, nil)
+	if s, _ := r.Find("a/new"); s.Order() != 1.5 {
+		t.Fatalf("Expected index %v, got %v", 1.5, s.Order())
+	}
+	// three and two share index 2 and are sorted by ID, no room between them
+	cs, err = r.InsertAfter("a/three", &Segment{ID: "tie"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, []string{"a/s_tie.md"}, []string{"a/s_two.md"}, nil)
+	for id, idx := range map[string]float64{"tie": 3, "two": 4, "four": 5} {
+		if s, _ := r.Find("a/" + id); s.Order() != idx {
+			t.Fatalf("%s: expected index %v, got %v", id, idx, s.Order())
+		}
+	}
+	if _, err := r.InsertBefore("a/one", &Segment{ID: "two"}); err == nil {
+		t.Fatal("Expected duplicate ID error")
+	}
+	cs, err = r.InsertBefore("b", &Category{ID: "z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, []string{"z/.category.yml"}, nil, nil)
+}
+
+func TestEditMoveRename(t *testing.T) {
+	r := editRoot(t)
+	cs, err := r.Move("a/four", "b/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, []string{"b/c/s_four.md"}, nil, []string{"a/s_four.md"})
+	if c := string(cs.Create[0].Contents); c != "---\nindex:   5\n---\nfour" {
+		t.Fatalf("Expected original contents, got %q", c)
+	}
+
+	cs, err = r.Rename("b", "d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs,
+		[]string{"d/.category.yml", "d/c/s_x.md", "d/c/s_four.md"}, nil,
+		[]string{"b/.category.yml", "b/c/s_x.md", "b/c/s_four.md"})
+	if _, ok := r.Find("d/c/four"); !ok {
+		t.Fatal("Expected d/c/four")
+	}
+	if _, err := r.Move("d", "d/c"); err == nil {
+		t.Fatal("Expected error moving into itself")
+	}
+	if _, err := r.Rename("a/one", "two"); err == nil {
+		t.Fatal("Expected duplicate ID error")
+	}
+	if _, ok := r.Find("a/one"); !ok {
+		t.Fatal("Expected a/one to be restored")
+	}
+}
+
+func TestEditReorderDelete(t *testing.T) {
+	r := editRoot(t)
+	cs, err := r.MoveBefore("a/four", "a/one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, nil, []string{"a/s_four.md"}, nil)
+	if c := r.Sub[0].Components[0].GetID(); c != "four" {
+		t.Fatalf("Expected %q first, got %q", "four", c)
+	}
+	cs, err = r.MoveAfter("b/c/x", "a/three")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, []string{"a/s_x.md"}, []string{"a/s_two.md"}, []string{"b/c/s_x.md"})
+	cs, err = r.Delete("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, nil, nil, []string{"b/.category.yml"})
+	if _, ok := r.Find("b"); ok {
+		t.Fatal("Expected b to be deleted")
+	}
+}
+
+func sortRoot(t *testing.T, category string) *Root {
+	items := []item.Memory{
+		{ID: "a/.category.yml", Contents: []byte(category)},
+		{ID: "a/s_one.md", Contents: []byte("---\nindex: 3\ntitle: one\n---\n")},
+		{ID: "a/s_two.md", Contents: []byte("---\nindex: 2\ntitle: two\n---\n")},
+		{ID: "a/s_three.md", Contents: []byte("---\nindex: 1\ntitle: three\n---\n")},
+	}
+	r, err := NewRoot(Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Decode(&source.Memory{Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// checkOrder verifies the order of the Components, that sorting must keep.
+func checkOrder(t *testing.T, c *Category, exp ...string) {
+	t.Helper()
+	for i := 0; i < 2; i++ {
+		var got []string
+		for _, cmp := range c.Components {
+			got = append(got, cmp.GetID())
+		}
+		if strings.Join(got, " ") != strings.Join(exp, " ") {
+			t.Fatalf("Expected %v, got %v", exp, got)
+		}
+		c.sort()
+	}
+}
+
+func TestEditSortDesc(t *testing.T) {
+	r := sortRoot(t, "sort: -index\n")
+	cs, err := r.InsertAfter("a/one", &Segment{ID: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, []string{"a/s_new.md"}, nil, nil)
+	checkOrder(t, &r.Sub[0], "one", "new", "two", "three")
+	if s, _ := r.Find("a/new"); s.Order() != 2.5 {
+		t.Fatalf("Expected index %v, got %v", 2.5, s.Order())
+	}
+	cs, err = r.MoveBefore("a/three", "a/one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, nil, []string{"a/s_three.md"}, nil)
+	checkOrder(t, &r.Sub[0], "three", "one", "new", "two")
+}
+
+func TestEditSortExplicit(t *testing.T) {
+	r := sortRoot(t, "order: [two, one]\n")
+	checkOrder(t, &r.Sub[0], "two", "one", "three")
+	cs, err := r.InsertAfter("a/two", &Segment{ID: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, []string{"a/s_new.md"}, []string{"a/.category.yml"}, nil)
+	checkOrder(t, &r.Sub[0], "two", "new", "one", "three")
+	if c := string(cs.Update[0].Contents); c != "order:\n- two\n- new\n- one\n" {
+		t.Fatalf("Expected updated order, got %q", c)
+	}
+	cs, err = r.MoveAfter("a/one", "a/three")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, nil, []string{"a/s_one.md", "a/.category.yml"}, nil)
+	checkOrder(t, &r.Sub[0], "two", "new", "three", "one")
+	if exp := []string{"two", "new"}; strings.Join(r.Sub[0].Explicit, " ") != strings.Join(exp, " ") {
+		t.Fatalf("Expected %v, got %v", exp, r.Sub[0].Explicit)
+	}
+}
+
+func TestEditSortTitle(t *testing.T) {
+	for _, s := range []string{"sort: title\n", "sort: -date\n"} {
+		r := sortRoot(t, s)
+		if _, err := r.InsertAfter("a/one", &Segment{ID: "new"}); err == nil {
+			t.Fatalf("%q: expected error", s)
+		}
+		if _, err := r.MoveBefore("a/three", "a/one"); err == nil {
+			t.Fatalf("%q: expected error", s)
+		}
+		if _, ok := r.Find("a/three"); !ok {
+			t.Fatalf("%q: expected a/three to be kept", s)
+		}
+	}
+}
+
+func TestEditSharedID(t *testing.T) {
+	items := []item.Memory{
+		{ID: "a/s_intro.md", Contents: []byte("---\nindex: 1\n---\n")},
+		{ID: "a/intro/s_x.md", Contents: []byte("---\nindex: 1\n---\n")},
+	}
+	r, err := NewRoot(Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Decode(&source.Memory{Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	if cmp, _ := r.Find("a/intro"); typeName(cmp) != "Segment" {
+		t.Fatalf("Expected Segment, got %s", typeName(cmp))
+	}
+	if cmp, _ := r.Find("a/intro/"); typeName(cmp) != "Category" {
+		t.Fatalf("Expected Category, got %s", typeName(cmp))
+	}
+	cs, err := r.Move("a/intro", "a/intro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs, []string{"a/intro/s_intro.md"}, nil, []string{"a/s_intro.md"})
+	if _, err := r.MoveBefore("a/intro/intro", "a/intro/"); err == nil {
+		t.Fatal("Expected error moving a Segment next to a Category")
+	}
+	cs, err = r.Rename("a/intro/", "guide")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkChangeSet(t, cs,
+		[]string{"a/guide/s_intro.md", "a/guide/s_x.md"}, nil,
+		[]string{"a/intro/s_intro.md", "a/intro/s_x.md"})
+	if _, err := r.Delete("a/guide/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Find("a/guide"); ok {
+		t.Fatal("Expected a/guide to be deleted")
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DecodeError is an error decoding an Item.
+type DecodeError struct {
+	// Path is the Item name.
+	Path string
+	// Type is the Component type, empty if unknown.
+	Type string
+	// Line and Column are the error position in the Item, 0 if unknown.
+	Line, Column int
+	Err          error
+}
+
+func (e *DecodeError) Error() string {
+	b := strings.Builder{}
+	b.WriteString(e.Path)
+	if e.Line != 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+		if e.Column != 0 {
+			fmt.Fprintf(&b, ":%d", e.Column)
+		}
+	}
+	if e.Type != "" {
+		fmt.Fprintf(&b, ": %s", e.Type)
+	}
+	fmt.Fprintf(&b, ": %s", e.Err)
+	return b.String()
+}
+
+// newDecodeError returns a DecodeError, extracting the position from err.
+func newDecodeError(path string, cmp Component, err error) *DecodeError {
+	e := DecodeError{Path: path, Err: err}
+	e.Type = typeName(cmp)
+	if p, ok := err.(*posError); ok {
+		e.Line, e.Column, e.Err = p.line, p.col, p.err
+	}
+	return &e
+}
+
+// typeName returns the Component type name, empty for nil.
+func typeName(cmp Component) string {
+	t := reflect.TypeOf(cmp)
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// Errors is a list of DecodeErrors.
+type Errors []*DecodeError
+
+func (e Errors) Error() string {
+	var s = make([]string, len(e))
+	for i := range e {
+		s[i] = e[i].Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// posError is an error at a given position of an Item.
+type posError struct {
+	line, col int
+	err       error
+}
+
+func (p *posError) Error() string {
+	return fmt.Sprintf("line %d: %s", p.line, p.err)
+}
+
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// yamlError adds the position to yaml errors, offset is the line where the
+// yaml document starts.
+func yamlError(err error, offset int) error {
+	var msg string
+	switch e := err.(type) {
+	case *yaml.TypeError:
+		if len(e.Errors) == 0 {
+			return err
+		}
+		msg = e.Errors[0]
+	default:
+		msg = err.Error()
+	}
+	m := yamlLine.FindStringSubmatch(msg)
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &posError{line: line + offset, err: fmt.Errorf("%s", msg[len(m[0]):])}
+}
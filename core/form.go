@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 
 	"gopkg.in/yaml.v2"
 )
@@ -15,11 +16,20 @@ type Form struct {
 	Index   float64           `yaml:"index,omitempty"`
 	Meta    map[string]string `yaml:",inline"`
 	Screens []FormScreen      `yaml:"screens"`
+
+	// src is the decoded YAML, Encode patches it.
+	src []byte
 }
 
 // GetID implements the Component interface.
 func (f *Form) GetID() string { return f.ID }
 
+// SetID implements the Renamer interface.
+func (f *Form) SetID(id string) { f.ID = id }
+
+// SetIndex implements the Indexer interface.
+func (f *Form) SetIndex(i float64) { f.Index = i }
+
 // Order implements the Component interface.
 func (f *Form) Order() float64 {
 	return f.Index
@@ -31,11 +41,7 @@ func (f Form) String() string {
 
 // Encode returns Item contents.
 func (f *Form) Encode() ([]byte, error) {
-	b := bytes.NewBuffer(nil)
-	if err := yaml.NewEncoder(b).Encode(f); err != nil {
-		return nil, err
-	}
-	return b.Bytes(), nil
+	return encodeYAML(f.src, f)
 }
 
 // Format implements the Decoder interface.
@@ -47,17 +53,132 @@ func (f *Form) Decode(id string, r io.Reader) (Component, error) {
 }
 
 func (*Form) decode(id string, r io.Reader) (*Form, error) {
-	c := Form{ID: id}
-	if err := yaml.NewDecoder(r).Decode(&c); err != nil {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	c := Form{ID: id, src: src}
+	if err := yaml.NewDecoder(bytes.NewReader(src)).Decode(&c); err != nil {
+		return nil, yamlError(err, 0)
+	}
+	if err := c.check(); err != nil {
 		return nil, err
 	}
 	return &c, nil
 }
 
+// check verifies the items, the syntax of the Conditions and the screens of
+// the Branches.
+func (f *Form) check() error {
+	names := make(map[string]bool)
+	for i, s := range f.Screens {
+		for _, item := range s.Items {
+			if err := item.check(); err != nil {
+				return fmt.Errorf("screen %d: item %q: %s", i, item.Name, err)
+			}
+			if names[item.Name] {
+				return fmt.Errorf("screen %d: item %q: duplicate name", i, item.Name)
+			}
+			names[item.Name] = true
+			if err := item.ShowIf.Check(); err != nil {
+				return fmt.Errorf("screen %d: item %q: show_if: %s", i, item.Name, err)
+			}
+		}
+		for _, b := range s.NextScreenIf {
+			if err := b.If.Check(); err != nil {
+				return fmt.Errorf("screen %d: next_screen_if: %s", i, err)
+			}
+			if _, err := f.screen(b.Screen); err != nil {
+				return fmt.Errorf("screen %d: next_screen_if: %s", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// screen returns the index of the screen with the given "name" Meta.
+func (f *Form) screen(name string) (int, error) {
+	for i, s := range f.Screens {
+		if Meta(s.Meta).String("name") == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown screen %q", name)
+}
+
+// Visible returns the items of a screen that are shown with the answers.
+func (f *Form) Visible(screen int, answers map[string]interface{}) ([]FormItem, error) {
+	if screen < 0 || screen >= len(f.Screens) {
+		return nil, fmt.Errorf("invalid screen %d", screen)
+	}
+	var items []FormItem
+	for _, item := range f.Screens[screen].Items {
+		ok, err := item.ShowIf.Eval(answers)
+		if err != nil {
+			return nil, fmt.Errorf("item %q: show_if: %s", item.Name, err)
+		}
+		if ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Next returns the screen that follows the given one with the answers: the
+// one of the first matching Branch, or the next in order. It returns -1 after
+// the last screen.
+func (f *Form) Next(screen int, answers map[string]interface{}) (int, error) {
+	if screen < 0 || screen >= len(f.Screens) {
+		return 0, fmt.Errorf("invalid screen %d", screen)
+	}
+	for _, b := range f.Screens[screen].NextScreenIf {
+		ok, err := b.If.Eval(answers)
+		if err != nil {
+			return 0, fmt.Errorf("screen %d: next_screen_if: %s", screen, err)
+		}
+		if ok {
+			return f.screen(b.Screen)
+		}
+	}
+	if screen++; screen == len(f.Screens) {
+		return -1, nil
+	}
+	return screen, nil
+}
+
+// Path returns the screens shown with the answers, in order.
+func (f *Form) Path(answers map[string]interface{}) ([]int, error) {
+	var (
+		path []int
+		seen = make(map[int]bool)
+	)
+	for i := 0; i != -1 && i < len(f.Screens); {
+		if seen[i] {
+			return nil, fmt.Errorf("screen %d: loop in next_screen_if", i)
+		}
+		seen[i] = true
+		path = append(path, i)
+		next, err := f.Next(i, answers)
+		if err != nil {
+			return nil, err
+		}
+		i = next
+	}
+	return path, nil
+}
+
 // FormScreen is a Form Screen
 type FormScreen struct {
 	Meta  Map        `yaml:",inline"`
 	Items []FormItem `yaml:"items"`
+	// NextScreenIf are the jumps to other screens, the first that matches wins.
+	NextScreenIf []Branch `yaml:"next_screen_if,omitempty"`
+}
+
+// Branch is a jump to the screen with the given "name" Meta.
+type Branch struct {
+	If     Condition `yaml:"if" json:"if,omitempty"`
+	Screen string    `yaml:"screen" json:"screen"`
 }
 
 // FormItem is form input
@@ -65,7 +186,9 @@ type FormItem struct {
 	Name     string `yaml:"name"`
 	Type     string `yaml:"type"`
 	Required bool   `yaml:"required,omitempty"`
-	Meta     Map    `yaml:",inline"`
+	// ShowIf hides the item if false.
+	ShowIf Condition `yaml:"show_if,omitempty"`
+	Meta   Map       `yaml:",inline"`
 }
 
 // Map works around JSON problem with interface{} keys.
@@ -73,17 +196,18 @@ type Map map[string]interface{}
 
 // MarshalJSON replaces interface{} keys with strings.
 func (m Map) MarshalJSON() ([]byte, error) {
-	return json.Marshal(yaml2json(m))
+	return json.Marshal(yaml2json(map[string]interface{}(m)))
 }
 
 // yaml2json fixes the interface{} keys in map recursively
 func yaml2json(v interface{}) interface{} {
 	switch v := v.(type) {
 	case map[string]interface{}:
-		for k, w := range v {
-			v[k] = yaml2json(w)
+		f := make(map[string]interface{}, len(v))
+		for k, v := range v {
+			f[k] = yaml2json(v)
 		}
-		return v
+		return f
 	case map[interface{}]interface{}:
 		f := make(map[string]interface{}, len(v))
 		for k, v := range v {
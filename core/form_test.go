@@ -89,3 +89,81 @@ title: form
 		}
 	}
 }
+
+func TestFormConditions(t *testing.T) {
+	src := `screens:
+- items:
+  - name: age
+    type: number
+  - name: school
+    type: text
+    required: true
+    show_if: age < 18
+  next_screen_if:
+  - if: age >= 65
+    screen: retired
+- name: work
+  items:
+  - name: job
+    type: text
+    required: true
+  next_screen_if:
+  - screen: end
+- name: retired
+  items:
+  - name: pension
+    type: number
+    required: true
+- name: end
+  items:
+  - name: notes
+    type: text
+`
+	f, err := (*Form).decode(nil, "a", bytes.NewBufferString(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		answers map[string]interface{}
+		items   int
+		path    []int
+		errs    []string
+	}{
+		{map[string]interface{}{"age": 10}, 2, []int{0, 1, 3}, []string{"school", "job"}},
+		{map[string]interface{}{"age": 30}, 1, []int{0, 1, 3}, []string{"job"}},
+		{map[string]interface{}{"age": 70, "school": "x"}, 1, []int{0, 2, 3}, []string{"pension"}},
+	} {
+		items, err := f.Visible(0, tc.answers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != tc.items {
+			t.Fatalf("%v: expected %v items, got %v", tc.answers, tc.items, items)
+		}
+		path, err := f.Path(tc.answers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(path, tc.path) {
+			t.Fatalf("%v: expected %v path, got %v", tc.answers, tc.path, path)
+		}
+		var errs []string
+		for _, e := range f.Validate(tc.answers) {
+			errs = append(errs, e.Field)
+		}
+		if !reflect.DeepEqual(errs, tc.errs) {
+			t.Fatalf("%v: expected %v errors, got %v", tc.answers, tc.errs, errs)
+		}
+	}
+	if n, err := f.Next(3, nil); err != nil || n != -1 {
+		t.Fatalf("Expected %v, got %v %v", -1, n, err)
+	}
+	for _, bad := range []string{
+		"screens:\n- items:\n  - name: a\n    show_if: a ==\n",
+		"screens:\n- next_screen_if:\n  - screen: nowhere\n",
+	} {
+		if _, err := (*Form).decode(nil, "a", bytes.NewBufferString(bad)); err == nil {
+			t.Fatalf("%q: expected error", bad)
+		}
+	}
+}
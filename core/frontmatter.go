@@ -0,0 +1,239 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// FrontMatter is the format of the Segment header.
+type FrontMatter int
+
+// Available FrontMatter formats.
+const (
+	// YAMLFrontMatter is delimited by "---" lines.
+	YAMLFrontMatter FrontMatter = iota
+	// TOMLFrontMatter is delimited by "+++" lines.
+	TOMLFrontMatter
+	// JSONFrontMatter is a JSON object at the start of the file.
+	JSONFrontMatter
+)
+
+func (f FrontMatter) String() string {
+	switch f {
+	case YAMLFrontMatter:
+		return "yaml"
+	case TOMLFrontMatter:
+		return "toml"
+	case JSONFrontMatter:
+		return "json"
+	default:
+		return fmt.Sprintf("FrontMatter(%d)", int(f))
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (f FrontMatter) MarshalText() ([]byte, error) { return []byte(f.String()), nil }
+
+// decodeMeta detects the front matter format and decodes it, returning the
+// reader for the body.
+func (s *Segment) decodeMeta(r *bufio.Reader) (io.Reader, error) {
+	if c, err := r.Peek(1); err == nil && c[0] == '{' {
+		s.FrontMatter = JSONFrontMatter
+		return s.decodeJSON(r)
+	}
+	f, header, err := extractMeta(r)
+	if err != nil {
+		return nil, err
+	}
+	s.FrontMatter, s.src = f, header
+	if f == TOMLFrontMatter {
+		return r, s.decodeTOML(header)
+	}
+	if err := yaml.NewDecoder(bytes.NewReader(header)).Decode(s); err != nil {
+		return nil, yamlError(err, 1)
+	}
+	return r, nil
+}
+
+// decodeJSON decodes a JSON object, followed by an optional newline.
+func (s *Segment) decodeJSON(r io.Reader) (io.Reader, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.decodeObject(b)
+	if err != nil {
+		return nil, err
+	}
+	s.src = b[:n]
+	body := b[n:]
+	if bytes.HasPrefix(body, []byte("\r\n")) {
+		body = body[2:]
+	} else if bytes.HasPrefix(body, []byte("\n")) {
+		body = body[1:]
+	}
+	return bytes.NewReader(body), nil
+}
+
+// decodeTOML sets Index and Meta from a TOML header.
+func (s *Segment) decodeTOML(header []byte) error {
+	var m map[string]interface{}
+	if _, err := toml.Decode(string(header), &m); err != nil {
+		if e, ok := err.(toml.ParseError); ok {
+			return &posError{line: e.Position.Line + 1, err: errors.New(e.Message)}
+		}
+		return err
+	}
+	return s.setMeta(m)
+}
+
+// decodeObject sets Index and Meta from the JSON object at the start of b,
+// returning its length.
+func (s *Segment) decodeObject(b []byte) (int64, error) {
+	var (
+		d = json.NewDecoder(bytes.NewReader(b))
+		m map[string]interface{}
+	)
+	if err := d.Decode(&m); err != nil {
+		var offset int64
+		switch e := err.(type) {
+		case *json.SyntaxError:
+			offset = e.Offset
+		case *json.UnmarshalTypeError:
+			offset = e.Offset
+		default:
+			return 0, err
+		}
+		return 0, &posError{line: bytes.Count(b[:offset], []byte("\n")) + 1, err: err}
+	}
+	return d.InputOffset(), s.setMeta(m)
+}
+
+// setMeta sets Index and Meta from a decoded map.
+func (s *Segment) setMeta(m map[string]interface{}) error {
+	if v, ok := m["index"]; ok {
+		switch v := v.(type) {
+		case int64:
+			s.Index = float64(v)
+		case float64:
+			s.Index = v
+		default:
+			return fmt.Errorf("invalid index %v", v)
+		}
+		delete(m, "index")
+	}
+	if len(m) != 0 {
+		s.Meta = m
+	}
+	return nil
+}
+
+// metaMap returns Index and Meta in a single map.
+func (s *Segment) metaMap() map[string]interface{} {
+	m := yaml2json(map[string]interface{}(s.Meta)).(map[string]interface{})
+	switch {
+	case s.Index == 0:
+	case s.Index == math.Trunc(s.Index):
+		m["index"] = int64(s.Index)
+	default:
+		m["index"] = s.Index
+	}
+	return m
+}
+
+// unchanged tells if Index and Meta are still the ones of the TOML or JSON
+// front matter that was decoded.
+func (s *Segment) unchanged() bool {
+	if s.src == nil {
+		return false
+	}
+	var (
+		o   Segment
+		err error
+	)
+	switch s.FrontMatter {
+	case TOMLFrontMatter:
+		err = o.decodeTOML(s.src)
+	case JSONFrontMatter:
+		_, err = o.decodeObject(s.src)
+	default:
+		return false
+	}
+	return err == nil && o.Index == s.Index && reflect.DeepEqual(o.Meta, s.Meta)
+}
+
+// encodeMeta writes the front matter in the Segment format. TOML and JSON are
+// written again only if they changed, to keep comments and order.
+func (s *Segment) encodeMeta(b *bytes.Buffer) error {
+	switch s.FrontMatter {
+	case TOMLFrontMatter:
+		fmt.Fprintln(b, "+++")
+		if s.unchanged() {
+			b.Write(s.src)
+		} else if err := toml.NewEncoder(b).Encode(s.metaMap()); err != nil {
+			return err
+		}
+		fmt.Fprintln(b, "+++")
+	case JSONFrontMatter:
+		if s.unchanged() {
+			b.Write(s.src)
+		} else {
+			j, err := json.MarshalIndent(s.metaMap(), "", "  ")
+			if err != nil {
+				return err
+			}
+			b.Write(j)
+		}
+		fmt.Fprintln(b)
+	default:
+		meta, err := encodeYAML(s.src, s)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(b, "---")
+		b.Write(meta)
+		fmt.Fprintln(b, "---")
+	}
+	return nil
+}
+
+// extractMeta looks for "---\n" or "+++\n" delimiters, returning what's between.
+func extractMeta(r *bufio.Reader) (FrontMatter, []byte, error) {
+	row, _, err := r.ReadLine()
+	if err != nil {
+		return 0, nil, err
+	}
+	var f FrontMatter
+	delim := bytes.TrimSuffix(row, []byte("\r"))
+	switch string(delim) {
+	case "---":
+		f = YAMLFrontMatter
+	case "+++":
+		f = TOMLFrontMatter
+	default:
+		return 0, nil, &posError{line: 1, err: errors.New("Invalid header")}
+	}
+	delim = append([]byte(nil), delim...)
+	b := bytes.NewBuffer(nil)
+	for {
+		row, _, err := r.ReadLine()
+		if err != nil {
+			return 0, nil, err
+		}
+		if bytes.Equal(delim, bytes.TrimSuffix(row, []byte("\r"))) {
+			break
+		}
+		fmt.Fprintln(b, string(row))
+	}
+	return f, b.Bytes(), nil
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-tent/tent/item"
+	"github.com/go-tent/tent/source"
+)
+
+func TestFrontMatter(t *testing.T) {
+	for _, tc := range []struct {
+		format FrontMatter
+		src    string
+		exp    string
+	}{
+		{TOMLFrontMatter,
+			"+++\ntitle = \"Hello\"\nindex = 2\ntags = [\"a\", \"b\"]\n+++\nbody",
+			"+++\nindex = 3\ntags = [\"a\", \"b\"]\ntitle = \"Hello\"\n+++\nbody"},
+		{JSONFrontMatter,
+			"{\"title\": \"Hello\", \"index\": 2, \"tags\": [\"a\", \"b\"]}\nbody",
+			"{\n  \"index\": 3,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ],\n  \"title\": \"Hello\"\n}\nbody"},
+		{YAMLFrontMatter,
+			"---\ntitle: Hello\nindex: 2\ntags: [a, b]\n---\nbody",
+			"---\ntitle: Hello\nindex: 3\ntags: [a, b]\n---\nbody"},
+	} {
+		s, err := (*Segment).decode(nil, "a", bytes.NewBufferString(tc.src))
+		if err != nil {
+			t.Fatalf("%s: %s", tc.format, err)
+		}
+		if s.FrontMatter != tc.format {
+			t.Fatalf("Expected %s, got %s", tc.format, s.FrontMatter)
+		}
+		if s.Index != 2 || s.Meta.String("title") != "Hello" || len(s.Meta.Strings("tags")) != 2 {
+			t.Fatalf("%s: unexpected %v", tc.format, s)
+		}
+		if string(s.Body) != "body" {
+			t.Fatalf("%s: expected %q, got %q", tc.format, "body", s.Body)
+		}
+		s.Index = 3
+		b, err := s.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.exp {
+			t.Fatalf("%s: expected %q, got %q", tc.format, tc.exp, b)
+		}
+	}
+}
+
+func TestFrontMatterUnchanged(t *testing.T) {
+	for _, src := range []string{
+		"+++\n# comment\ntitle = \"Hello\"\nindex = 2\n+++\nbody",
+		"{\"title\": \"Hello\", \"index\": 2, \"a\": {\"z\": 1, \"b\": 2}}\nbody",
+	} {
+		s, err := (*Segment).decode(nil, "a", bytes.NewBufferString(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := s.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != src {
+			t.Fatalf("Expected %q, got %q", src, b)
+		}
+	}
+}
+
+func TestFrontMatterError(t *testing.T) {
+	for src, line := range map[string]int{
+		"+++\ntitle = \"a\"\ntitle = \"b\"\n+++\n": 3,
+		"{\n\"title\": \"a\",\n}\n":                3,
+		"title: a\n":                               1,
+	} {
+		_, err := (*Segment).decode(nil, "a", bytes.NewBufferString(src))
+		p, ok := err.(*posError)
+		if !ok {
+			t.Fatalf("%q: expected position error, got %v", src, err)
+		}
+		if p.line != line {
+			t.Fatalf("%q: expected line %d, got %d", src, line, p.line)
+		}
+	}
+}
+
+func TestNormalizeFrontMatter(t *testing.T) {
+	items := []item.Memory{
+		{ID: "s_a.md", Contents: []byte("+++\ntitle = \"A\"\n+++\nbody")},
+		{ID: "s_b.md", Contents: []byte("---\ntitle: B\n---\nbody")},
+	}
+	r, err := NewRootOptions(Options{NormalizeFrontMatter: true}, Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Decode(&source.Memory{Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	src, err := r.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]string{
+		"s_a.md": "---\ntitle: A\n---\nbody",
+		"s_b.md": "---\ntitle: B\n---\nbody",
+	}
+	for i, err := src.Next(); i != nil; i, err = src.Next() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := string(i.(item.Memory).Contents); c != exp[i.Name()] {
+			t.Fatalf("%s: expected %q, got %q", i.Name(), exp[i.Name()], c)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"path"
+	"sort"
+)
+
+// MetaValue is an effective Meta value.
+type MetaValue struct {
+	Value interface{}
+	// Source is the path of the element that defines the value, "" for the
+	// Category where the lookup starts.
+	Source string
+}
+
+// EffectiveMeta is the Meta of an element merged with the one of its parents.
+type EffectiveMeta map[string]MetaValue
+
+// Meta returns the values without their sources.
+func (e EffectiveMeta) Meta() Meta {
+	m := make(Meta, len(e))
+	for k, v := range e {
+		m[k] = v.Value
+	}
+	return m
+}
+
+// Keys returns the sorted keys.
+func (e EffectiveMeta) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Inherited returns the values that are not defined by the element at p.
+func (e EffectiveMeta) Inherited(p string) EffectiveMeta {
+	n := make(EffectiveMeta)
+	for k, v := range e {
+		if v.Source != cleanPath(p) {
+			n[k] = v
+		}
+	}
+	return n
+}
+
+// EffectiveMeta returns the Meta of the element at path p, relative to c,
+// with the values inherited from the Categories on top of it. A null value
+// unsets an inherited key, for Checks and Forms an empty string does.
+func (c *Category) EffectiveMeta(p string) (EffectiveMeta, bool) {
+	parents, cmp, ok := c.locate(p)
+	if !ok {
+		return nil, false
+	}
+	var (
+		e   = make(EffectiveMeta)
+		src string
+	)
+	for i, cat := range parents {
+		if i > 0 {
+			src = path.Join(src, cat.ID)
+		}
+		e.apply(cat.Meta, src)
+	}
+	e.apply(metaOf(cmp), cleanPath(p))
+	return e, true
+}
+
+// with returns a copy of the EffectiveMeta with m applied.
+func (e EffectiveMeta) with(m Meta, src string) EffectiveMeta {
+	n := make(EffectiveMeta, len(e)+len(m))
+	for k, v := range e {
+		n[k] = v
+	}
+	n.apply(m, src)
+	return n
+}
+
+func (e EffectiveMeta) apply(m Meta, src string) {
+	for k, v := range m {
+		if v == nil {
+			delete(e, k)
+			continue
+		}
+		e[k] = MetaValue{Value: v, Source: src}
+	}
+}
+
+// metaOf returns the Meta of a Component, nil if it has none.
+func metaOf(cmp Component) Meta {
+	var values map[string]string
+	switch v := cmp.(type) {
+	case *Category:
+		return v.Meta
+	case *Segment:
+		return v.Meta
+	case *Checks:
+		values = v.Meta
+	case *Form:
+		values = v.Meta
+	default:
+		return nil
+	}
+	m := make(Meta, len(values))
+	for k, v := range values {
+		if v == "" {
+			m[k] = nil
+			continue
+		}
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEffectiveMeta(t *testing.T) {
+	root := Category{ID: "root", Meta: Meta{"author": "team", "layout": "page"}, Sub: []Category{
+		{ID: "news", Meta: Meta{"layout": "post", "audience": "all"}, Components: []Component{
+			&Segment{ID: "one", Meta: Meta{"title": "One", "audience": nil}},
+			&Checks{ID: "list", Meta: map[string]string{"author": "", "title": "List"}},
+		}},
+	}}
+	e, ok := root.EffectiveMeta("news/one")
+	if !ok {
+		t.Fatal("Expected news/one")
+	}
+	exp := EffectiveMeta{
+		"author": {Value: "team", Source: ""},
+		"layout": {Value: "post", Source: "news"},
+		"title":  {Value: "One", Source: "news/one"},
+	}
+	if !reflect.DeepEqual(e, exp) {
+		t.Fatalf("Expected %v, got %v", exp, e)
+	}
+	if got := e.Inherited("news/one").Keys(); !reflect.DeepEqual(got, []string{"author", "layout"}) {
+		t.Fatalf("Expected inherited author and layout, got %v", got)
+	}
+	if m := e.Meta(); m.String("layout") != "post" {
+		t.Fatalf("Expected post layout, got %v", m)
+	}
+
+	e, _ = root.EffectiveMeta("news/list")
+	if got := e.Keys(); !reflect.DeepEqual(got, []string{"audience", "layout", "title"}) {
+		t.Fatalf("Expected audience, layout and title, got %v", got)
+	}
+	e, _ = root.EffectiveMeta("news")
+	if v := e["audience"]; v.Source != "news" || v.Value != "all" {
+		t.Fatalf("Expected audience from news, got %v", v)
+	}
+	if _, ok := root.EffectiveMeta("news/missing"); ok {
+		t.Fatal("Expected missing element")
+	}
+}
+
+func TestEffectiveMetaUnset(t *testing.T) {
+	s, err := (*Segment).decode(nil, "one", bytes.NewBufferString("---\nauthor: ~\n---\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := Category{ID: "root", Meta: Meta{"author": "team"}, Components: []Component{s}}
+	e, _ := root.EffectiveMeta("one")
+	if v, ok := e["author"]; ok {
+		t.Fatalf("Expected no author, got %v", v)
+	}
+}
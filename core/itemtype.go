@@ -0,0 +1,194 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// ItemType describes a FormItem type.
+type ItemType struct {
+	// Kind is the kind of the answers: string, number, bool, date or list.
+	// Items of bool kind with the "option" Meta, like checkboxes, have a list
+	// of options as answers.
+	Kind string
+	// Meta are the allowed Meta keys, besides label, description and
+	// placeholder. Required keys are allowed too.
+	Meta     []string
+	Required []string
+	// Check verifies the Meta of an item, it can be nil.
+	Check func(item *FormItem) error
+	// Validate checks a non empty answer, it can be nil.
+	Validate func(item *FormItem, v interface{}) *FieldError
+	// Schema adds the Meta constraints to the JSON Schema of an item, whose
+	// type comes from the Kind. It can be nil.
+	Schema func(item *FormItem, s *JSONSchema)
+}
+
+// commonItemMeta are the Meta keys allowed for all types.
+var commonItemMeta = []string{"label", "description", "placeholder"}
+
+var itemTypes = struct {
+	sync.RWMutex
+	m map[string]ItemType
+}{m: map[string]ItemType{
+	"text":     {Kind: "string", Meta: []string{"min", "max", "pattern"}, Check: checkTextMeta, Validate: checkText, Schema: textSchema},
+	"email":    {Kind: "string", Meta: []string{"min", "max", "pattern"}, Check: checkTextMeta, Validate: checkEmail, Schema: emailSchema},
+	"number":   {Kind: "number", Meta: []string{"min", "max"}, Check: checkNumberMeta, Validate: checkNumber, Schema: numberSchema},
+	"date":     {Kind: "date", Meta: []string{"min", "max"}, Check: checkDateMeta, Validate: checkDate},
+	"select":   {Kind: "string", Required: []string{"option"}, Check: checkOptionMeta, Validate: checkSelect},
+	"radio":    {Kind: "string", Required: []string{"option"}, Check: checkOptionMeta, Validate: checkSelect},
+	"checkbox": {Kind: "bool", Meta: []string{"option", "min", "max"}, Check: checkCheckboxMeta, Validate: checkCheckbox, Schema: checkboxSchema},
+}}
+
+// RegisterItemType adds a FormItem type, it panics if the name is in use.
+func RegisterItemType(name string, t ItemType) {
+	itemTypes.Lock()
+	defer itemTypes.Unlock()
+	if _, ok := itemTypes.m[name]; ok {
+		panic(fmt.Sprintf("item type %q already registered", name))
+	}
+	switch t.Kind {
+	case "string", "number", "bool", "date", "list":
+	default:
+		panic(fmt.Sprintf("item type %q: invalid kind %q", name, t.Kind))
+	}
+	itemTypes.m[name] = t
+}
+
+// LookupItemType returns a registered FormItem type.
+func LookupItemType(name string) (ItemType, bool) {
+	itemTypes.RLock()
+	defer itemTypes.RUnlock()
+	t, ok := itemTypes.m[name]
+	return t, ok
+}
+
+// ItemTypes returns the sorted names of the registered types.
+func ItemTypes() []string {
+	itemTypes.RLock()
+	defer itemTypes.RUnlock()
+	names := make([]string, 0, len(itemTypes.m))
+	for k := range itemTypes.m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Kind returns the kind of the answers to the item, "" if the type is unknown.
+func (item *FormItem) Kind() string {
+	t, ok := LookupItemType(item.Type)
+	if !ok {
+		return ""
+	}
+	if t.Kind == "bool" && len(Meta(item.Meta).Strings("option")) != 0 {
+		return "list"
+	}
+	return t.Kind
+}
+
+// check verifies the type and the Meta of the item.
+func (item *FormItem) check() error {
+	if item.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	t, ok := LookupItemType(item.Type)
+	if !ok {
+		return fmt.Errorf("unknown type %q", item.Type)
+	}
+	allowed := make(map[string]bool)
+	for _, l := range [][]string{commonItemMeta, t.Meta, t.Required} {
+		for _, k := range l {
+			allowed[k] = true
+		}
+	}
+	keys := make([]string, 0, len(item.Meta))
+	for k := range item.Meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !allowed[k] {
+			return fmt.Errorf("%s: unknown key %q", item.Type, k)
+		}
+	}
+	for _, k := range t.Required {
+		if _, ok := item.Meta[k]; !ok {
+			return fmt.Errorf("%s: missing %q", item.Type, k)
+		}
+	}
+	if t.Check == nil {
+		return nil
+	}
+	if err := t.Check(item); err != nil {
+		return fmt.Errorf("%s: %s", item.Type, err)
+	}
+	return nil
+}
+
+func checkTextMeta(item *FormItem) error {
+	if err := checkNumberMeta(item); err != nil {
+		return err
+	}
+	v, ok := item.Meta["pattern"]
+	if !ok {
+		return nil
+	}
+	p, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("pattern: expected string, got %v", v)
+	}
+	if _, err := regexp.Compile(p); err != nil {
+		return fmt.Errorf("pattern: %s", err)
+	}
+	return nil
+}
+
+func checkNumberMeta(item *FormItem) error {
+	for _, k := range []string{"min", "max"} {
+		if v, ok := item.Meta[k]; ok {
+			if _, ok := number(v); !ok {
+				return fmt.Errorf("%s: expected number, got %v", k, v)
+			}
+		}
+	}
+	return nil
+}
+
+func checkDateMeta(item *FormItem) error {
+	for _, k := range []string{"min", "max"} {
+		if _, ok := item.Meta[k]; !ok {
+			continue
+		}
+		if _, ok := Meta(item.Meta).Time(k); !ok {
+			return fmt.Errorf("%s: expected date, got %v", k, item.Meta[k])
+		}
+	}
+	return nil
+}
+
+func checkOptionMeta(item *FormItem) error {
+	v, ok := item.Meta["option"]
+	if !ok {
+		return nil
+	}
+	l, ok := v.([]interface{})
+	if !ok || len(l) == 0 {
+		return fmt.Errorf("option: expected a list, got %v", v)
+	}
+	for _, o := range l {
+		if _, ok := scalar(o); !ok {
+			return fmt.Errorf("option: expected a value, got %v", o)
+		}
+	}
+	return nil
+}
+
+func checkCheckboxMeta(item *FormItem) error {
+	if err := checkOptionMeta(item); err != nil {
+		return err
+	}
+	return checkNumberMeta(item)
+}
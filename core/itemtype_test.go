@@ -0,0 +1,72 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// registerItemType registers a FormItem type, removing it at the end of the test.
+func registerItemType(t *testing.T, name string, it ItemType) {
+	RegisterItemType(name, it)
+	t.Cleanup(func() {
+		itemTypes.Lock()
+		defer itemTypes.Unlock()
+		delete(itemTypes.m, name)
+	})
+}
+
+func TestItemType(t *testing.T) {
+	registerItemType(t, "rating", ItemType{
+		Kind: "number",
+		Meta: []string{"stars"},
+		Validate: func(item *FormItem, v interface{}) *FieldError {
+			n, ok := number(v)
+			if max, _ := metaNumber(item.Meta, "stars"); !ok || n < 1 || n > max {
+				return &FieldError{Rule: RuleMax, Message: "invalid rating"}
+			}
+			return nil
+		},
+	})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("Expected panic")
+			}
+		}()
+		RegisterItemType("text", ItemType{Kind: "string"})
+	}()
+	f, err := (*Form).decode(nil, "a", bytes.NewBufferString("screens:\n- items:\n  - name: a\n    type: rating\n    stars: 5\n    label: A\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := f.Screens[0].Items[0].Kind(); k != "number" {
+		t.Fatalf("Expected %v, got %v", "number", k)
+	}
+	if errs := f.Validate(map[string]interface{}{"a": 6}); len(errs) != 1 || errs[0].Field != "a" {
+		t.Fatalf("Expected error for %v, got %v", "a", errs)
+	}
+	for item, exp := range map[string]string{
+		"name: a\n    type: chekbox":                           `item "a": unknown type "chekbox"`,
+		"type: text":                                           `item "": missing name`,
+		"name: a\n    type: select":                            `item "a": select: missing "option"`,
+		"name: a\n    type: select\n    option: x":             `item "a": select: option: expected a list, got x`,
+		"name: a\n    type: text\n    size: 3":                 `item "a": text: unknown key "size"`,
+		"name: a\n    type: text\n    pattern: '[a'":           `item "a": text: pattern: error parsing regexp`,
+		"name: a\n    type: number\n    min: low":              `item "a": number: min: expected number, got low`,
+		"name: a\n    type: date\n    max: soon":               `item "a": date: max: expected date, got soon`,
+		"name: a\n    type: text\n  - name: a\n    type: text": `item "a": duplicate name`,
+	} {
+		_, err := (*Form).decode(nil, "a", bytes.NewBufferString("screens:\n- items:\n  - "+item+"\n"))
+		if err == nil || !strings.Contains(err.Error(), exp) {
+			t.Fatalf("%q: expected %q, got %v", item, exp, err)
+		}
+	}
+	if got := ItemTypes(); !strings.Contains(strings.Join(got, " "), "checkbox date email number radio rating select text") {
+		t.Fatalf("Unexpected types %v", got)
+	}
+	item := FormItem{Type: "checkbox", Meta: Map{"option": []interface{}{"x"}}}
+	if k := item.Kind(); k != "list" {
+		t.Fatalf("Expected %v, got %v", "list", k)
+	}
+}
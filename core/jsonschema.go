@@ -0,0 +1,173 @@
+package core
+
+// JSONSchemaDraft is the "$schema" of the Form JSON Schemas.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON Schema document, with the keywords used for Forms.
+type JSONSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Format      string `json:"format,omitempty"`
+
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+
+	Items       *JSONSchema   `json:"items,omitempty"`
+	MinItems    *float64      `json:"minItems,omitempty"`
+	MaxItems    *float64      `json:"maxItems,omitempty"`
+	UniqueItems bool          `json:"uniqueItems,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Const       interface{}   `json:"const,omitempty"`
+
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *float64 `json:"minLength,omitempty"`
+	MaxLength *float64 `json:"maxLength,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+
+	// ShowIf is the show_if annotation of an item.
+	ShowIf Condition `json:"x-show-if,omitempty"`
+	// Screens is the annotation with the screens of a Form.
+	Screens []JSONScreen `json:"x-screens,omitempty"`
+}
+
+// JSONScreen is a FormScreen in a JSON Schema, Items are the property names.
+type JSONScreen struct {
+	Meta         Map      `json:"meta,omitempty"`
+	Items        []string `json:"items"`
+	NextScreenIf []Branch `json:"next_screen_if,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of the Form answers.
+// Items hidden by show_if or in screens skipped by next_screen_if are not
+// in "required", the x-screens annotation keeps screens and conditions.
+func (f *Form) JSONSchema() *JSONSchema {
+	var (
+		no = false
+		s  = JSONSchema{
+			Schema:               JSONSchemaDraft,
+			Title:                f.Meta["title"],
+			Description:          f.Meta["description"],
+			Type:                 "object",
+			Properties:           make(map[string]*JSONSchema),
+			AdditionalProperties: &no,
+		}
+		skippable = f.skippable()
+	)
+	for i, screen := range f.Screens {
+		js := JSONScreen{Meta: screen.Meta, Items: []string{}, NextScreenIf: screen.NextScreenIf}
+		for j := range screen.Items {
+			item := &screen.Items[j]
+			s.Properties[item.Name] = item.jsonSchema()
+			js.Items = append(js.Items, item.Name)
+			if item.Required && item.ShowIf == "" && !skippable[i] {
+				s.Required = append(s.Required, item.Name)
+				if item.Kind() == "bool" {
+					// a required checkbox must be checked
+					s.Properties[item.Name].Const = true
+				}
+			}
+		}
+		s.Screens = append(s.Screens, js)
+	}
+	return &s
+}
+
+// skippable returns the screens that a Branch can jump over.
+func (f *Form) skippable() map[int]bool {
+	m := make(map[int]bool)
+	for i, s := range f.Screens {
+		for _, b := range s.NextScreenIf {
+			k, err := f.screen(b.Screen)
+			if err != nil {
+				continue
+			}
+			for j := i + 1; j < k; j++ {
+				m[j] = true
+			}
+		}
+	}
+	return m
+}
+
+// jsonSchema returns the schema of the answer to the item.
+func (item *FormItem) jsonSchema() *JSONSchema {
+	s := JSONSchema{
+		Title:       Meta(item.Meta).String("label"),
+		Description: Meta(item.Meta).String("description"),
+		ShowIf:      item.ShowIf,
+	}
+	options, _ := yaml2json(item.Meta["option"]).([]interface{})
+	switch item.Kind() {
+	case "string":
+		s.Type, s.Enum = optionsType(options, "string"), options
+	case "number":
+		s.Type, s.Enum = optionsType(options, "number"), options
+	case "bool":
+		s.Type = "boolean"
+	case "date":
+		s.Type, s.Format = "string", "date"
+	case "list":
+		s.Type, s.UniqueItems = "array", true
+		s.Items = &JSONSchema{Type: optionsType(options, "string"), Enum: options}
+	}
+	if t, ok := LookupItemType(item.Type); ok && t.Schema != nil {
+		t.Schema(item, &s)
+	}
+	return &s
+}
+
+// optionsType returns the JSON type of the options, t if there are none and
+// "" if they have different types.
+func optionsType(options []interface{}, t string) string {
+	for i, o := range options {
+		var ot string
+		switch o.(type) {
+		case string:
+			ot = "string"
+		case int, int64, uint64, float64:
+			ot = "number"
+		case bool:
+			ot = "boolean"
+		}
+		if i != 0 && ot != t {
+			return ""
+		}
+		t = ot
+	}
+	return t
+}
+
+// metaFloat returns a pointer to a numeric Meta value, nil if missing.
+func metaFloat(m Map, key string) *float64 {
+	n, ok := metaNumber(m, key)
+	if !ok {
+		return nil
+	}
+	return &n
+}
+
+func textSchema(item *FormItem, s *JSONSchema) {
+	s.MinLength, s.MaxLength = metaFloat(item.Meta, "min"), metaFloat(item.Meta, "max")
+	if p, ok := item.Meta["pattern"].(string); ok {
+		s.Pattern = "^(?:" + p + ")$"
+	}
+}
+
+func emailSchema(item *FormItem, s *JSONSchema) {
+	textSchema(item, s)
+	s.Format = "email"
+}
+
+func numberSchema(item *FormItem, s *JSONSchema) {
+	s.Minimum, s.Maximum = metaFloat(item.Meta, "min"), metaFloat(item.Meta, "max")
+}
+
+func checkboxSchema(item *FormItem, s *JSONSchema) {
+	if s.Type == "array" {
+		s.MinItems, s.MaxItems = metaFloat(item.Meta, "min"), metaFloat(item.Meta, "max")
+	}
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestFormJSONSchema(t *testing.T) {
+	src := `title: Survey
+screens:
+- title: You
+  items:
+  - name: name
+    type: text
+    required: true
+    label: Name
+    max: 20
+    pattern: "[a-z]+"
+  - name: email
+    type: email
+    required: true
+    show_if: name != ""
+  - name: color
+    type: select
+    option: [red, blue]
+  next_screen_if:
+  - if: color == "red"
+    screen: end
+- items:
+  - name: age
+    type: number
+    required: true
+    min: 18
+- name: end
+  items:
+  - name: tags
+    type: checkbox
+    required: true
+    option: [a, b]
+    max: 1
+  - name: day
+    type: date
+`
+	f, err := (*Form).decode(nil, "a", bytes.NewBufferString(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.MarshalIndent(f.JSONSchema(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Survey",
+  "type": "object",
+  "properties": {
+    "age": {
+      "type": "number",
+      "minimum": 18
+    },
+    "color": {
+      "type": "string",
+      "enum": [
+        "red",
+        "blue"
+      ]
+    },
+    "day": {
+      "type": "string",
+      "format": "date"
+    },
+    "email": {
+      "type": "string",
+      "format": "email",
+      "x-show-if": "name != \"\""
+    },
+    "name": {
+      "title": "Name",
+      "type": "string",
+      "maxLength": 20,
+      "pattern": "^(?:[a-z]+)$"
+    },
+    "tags": {
+      "type": "array",
+      "items": {
+        "type": "string",
+        "enum": [
+          "a",
+          "b"
+        ]
+      },
+      "maxItems": 1,
+      "uniqueItems": true
+    }
+  },
+  "required": [
+    "name",
+    "tags"
+  ],
+  "additionalProperties": false,
+  "x-screens": [
+    {
+      "meta": {
+        "title": "You"
+      },
+      "items": [
+        "name",
+        "email",
+        "color"
+      ],
+      "next_screen_if": [
+        {
+          "if": "color == \"red\"",
+          "screen": "end"
+        }
+      ]
+    },
+    {
+      "items": [
+        "age"
+      ]
+    },
+    {
+      "meta": {
+        "name": "end"
+      },
+      "items": [
+        "tags",
+        "day"
+      ]
+    }
+  ]
+}`
+	if string(b) != exp {
+		t.Fatalf("Expected %s, got %s", exp, b)
+	}
+}
+
+func TestFormJSONSchemaOptions(t *testing.T) {
+	src := `screens:
+- items:
+  - name: size
+    type: select
+    option: [1, 2, 3]
+  - name: answer
+    type: radio
+    option: [maybe, 1]
+  - name: days
+    type: checkbox
+    option: [1, 7]
+  - name: terms
+    type: checkbox
+    required: true
+  - name: news
+    type: checkbox
+`
+	f, err := (*Form).decode(nil, "a", bytes.NewBufferString(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := f.JSONSchema()
+	for name, exp := range map[string]string{
+		"size":   `{"type":"number","enum":[1,2,3]}`,
+		"answer": `{"enum":["maybe",1]}`,
+		"days":   `{"type":"array","items":{"type":"number","enum":[1,7]},"uniqueItems":true}`,
+		"terms":  `{"type":"boolean","const":true}`,
+		"news":   `{"type":"boolean"}`,
+	} {
+		b, err := json.Marshal(s.Properties[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != exp {
+			t.Fatalf("%s: expected %s, got %s", name, exp, b)
+		}
+	}
+	if errs := f.Validate(map[string]interface{}{"terms": false}); len(errs) != 1 || errs[0].Field != "terms" {
+		t.Fatalf("Expected error for %v, got %v", "terms", errs)
+	}
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Meta contains the attributes of a Segment or Category, values can be any
+// YAML value: scalars, lists or nested maps.
+type Meta map[string]interface{}
+
+// MarshalJSON replaces interface{} keys of nested maps with strings.
+func (m Meta) MarshalJSON() ([]byte, error) {
+	return json.Marshal(yaml2json(map[string]interface{}(m)))
+}
+
+// String returns a scalar value as string, or "" if missing or not a scalar.
+func (m Meta) String(key string) string {
+	v, ok := m[key]
+	if !ok {
+		return ""
+	}
+	s, _ := scalar(v)
+	return s
+}
+
+// Strings returns a list of scalars, a single scalar is a list of one.
+func (m Meta) Strings(key string) []string {
+	switch v := m[key].(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var list = make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := scalar(e); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	case []string:
+		return v
+	default:
+		if s, ok := scalar(v); ok {
+			return []string{s}
+		}
+		return nil
+	}
+}
+
+// Bool returns a boolean value, false if missing or not a boolean.
+func (m Meta) Bool(key string) bool {
+	b, _ := m[key].(bool)
+	return b
+}
+
+// timeFormats are the timestamp formats allowed by YAML.
+var timeFormats = []string{
+	"2006-1-2T15:4:5.999999999Z07:00",
+	"2006-1-2t15:4:5.999999999Z07:00",
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2",
+}
+
+// Time returns a date or timestamp value.
+func (m Meta) Time(key string) (time.Time, bool) {
+	switch v := m[key].(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, f := range timeFormats {
+			if t, err := time.Parse(f, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Map returns a nested map, nil if missing or not a map.
+func (m Meta) Map(key string) Meta {
+	switch v := m[key].(type) {
+	case map[string]interface{}:
+		return Meta(v)
+	case Meta:
+		return v
+	case map[interface{}]interface{}:
+		n := make(Meta, len(v))
+		for k, v := range v {
+			n[fmt.Sprint(k)] = v
+		}
+		return n
+	}
+	return nil
+}
+
+// scalar formats v if it is not a list or a map.
+func scalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case nil, []interface{}, map[interface{}]interface{}, map[string]interface{}, Meta:
+		return "", false
+	case string:
+		return v, true
+	case time.Time:
+		return v.Format(time.RFC3339), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMeta(t *testing.T) {
+	s, err := (*Segment).decode(nil, "a", bytes.NewReader([]byte(`---
+title: Hello
+tags: [go, yaml]
+author: me
+draft: true
+published: 2019-03-04
+seo:
+  title: Hello world
+  weight: 2
+---
+body`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := s.Meta.String("title"); v != "Hello" {
+		t.Fatalf("Expected %q, got %q", "Hello", v)
+	}
+	if v := s.Meta.String("tags"); v != "" {
+		t.Fatalf("Expected empty string, got %q", v)
+	}
+	if v, exp := s.Meta.Strings("tags"), []string{"go", "yaml"}; !reflect.DeepEqual(v, exp) {
+		t.Fatalf("Expected %v, got %v", exp, v)
+	}
+	if v, exp := s.Meta.Strings("author"), []string{"me"}; !reflect.DeepEqual(v, exp) {
+		t.Fatalf("Expected %v, got %v", exp, v)
+	}
+	if v := s.Meta.Strings("missing"); v != nil {
+		t.Fatalf("Expected nil, got %v", v)
+	}
+	if !s.Meta.Bool("draft") {
+		t.Fatalf("Expected draft")
+	}
+	v, ok := s.Meta.Time("published")
+	if exp := time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC); !ok || !v.Equal(exp) {
+		t.Fatalf("Expected %v, got %v", exp, v)
+	}
+	if _, ok := s.Meta.Time("title"); ok {
+		t.Fatalf("Expected no time for title")
+	}
+	seo := s.Meta.Map("seo")
+	if seo.String("title") != "Hello world" || seo.String("weight") != "2" {
+		t.Fatalf("Expected seo title and weight, got %v", seo)
+	}
+
+	b, err := json.Marshal(s.Meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if seo, ok := got["seo"].(map[string]interface{}); !ok || seo["title"] != "Hello world" {
+		t.Fatalf("Expected seo in %s", b)
+	}
+
+	enc, err := s.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := (*Segment).decode(nil, "a", bytes.NewReader(enc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Meta, s2.Meta) {
+		t.Fatalf("Expected %v, got %v", s.Meta, s2.Meta)
+	}
+}
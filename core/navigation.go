@@ -0,0 +1,132 @@
+package core
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// SkipCategory is used as a return value from WalkFunc to skip the contents
+// of a Category.
+var SkipCategory = errors.New("skip this category")
+
+// WalkFunc is called by Walk for each Category and Component. Path is made of
+// IDs separated by "/", parents is the chain of Categories from the starting one.
+type WalkFunc func(path string, parents []*Category, cmp Component) error
+
+// Walk visits the tree in order, calling fn for each Category and Component,
+// the starting Category excluded. Any error from fn other than SkipCategory
+// stops the walk and is returned.
+func (c *Category) Walk(fn WalkFunc) error {
+	return c.walk("", []*Category{c}, fn)
+}
+
+func (c *Category) walk(prefix string, parents []*Category, fn WalkFunc) error {
+	for _, cmp := range c.Components {
+		if err := fn(path.Join(prefix, cmp.GetID()), parents, cmp); err != nil {
+			if err == SkipCategory {
+				continue
+			}
+			return err
+		}
+	}
+	for i := range c.Sub {
+		sub := &c.Sub[i]
+		p := path.Join(prefix, sub.ID)
+		switch err := fn(p, parents, sub); err {
+		case nil:
+		case SkipCategory:
+			continue
+		default:
+			return err
+		}
+		if err := sub.walk(p, append(parents[:len(parents):len(parents)], sub), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the Component or Category for the path, relative to c. A
+// Component comes before a Category with the same ID, a trailing "/" selects
+// the Category.
+func (c *Category) Find(p string) (Component, bool) {
+	_, cmp, ok := c.locate(p)
+	return cmp, ok
+}
+
+// Breadcrumbs returns the chain of Categories from c to the parent of the
+// element at the given path.
+func (c *Category) Breadcrumbs(p string) ([]*Category, bool) {
+	parents, _, ok := c.locate(p)
+	return parents, ok
+}
+
+// Siblings returns the elements before and after the one at the given path,
+// Components and Categories are siblings of their own kind only.
+func (c *Category) Siblings(p string) (prev, next Component, ok bool) {
+	parents, cmp, ok := c.locate(p)
+	if !ok || len(parents) == 0 {
+		return nil, nil, false
+	}
+	parent := parents[len(parents)-1]
+	if cat, isCat := cmp.(*Category); isCat {
+		for i := range parent.Sub {
+			if &parent.Sub[i] != cat {
+				continue
+			}
+			if i > 0 {
+				prev = &parent.Sub[i-1]
+			}
+			if i < len(parent.Sub)-1 {
+				next = &parent.Sub[i+1]
+			}
+		}
+		return prev, next, true
+	}
+	for i := range parent.Components {
+		if parent.Components[i].GetID() != cmp.GetID() {
+			continue
+		}
+		if i > 0 {
+			prev = parent.Components[i-1]
+		}
+		if i < len(parent.Components)-1 {
+			next = parent.Components[i+1]
+		}
+	}
+	return prev, next, true
+}
+
+// locate returns the element at path and the chain of its parents, a trailing
+// "/" excludes Components.
+func (c *Category) locate(p string) ([]*Category, Component, bool) {
+	isCat := strings.HasSuffix(p, "/")
+	p = strings.Trim(path.Clean("/"+p), "/")
+	if p == "" {
+		return nil, c, true
+	}
+	var (
+		parents []*Category
+		ids     = strings.Split(p, "/")
+	)
+next:
+	for n, id := range ids {
+		parents = append(parents, c)
+		if n == len(ids)-1 && !isCat {
+			for _, cmp := range c.Components {
+				if cmp.GetID() == id {
+					return parents, cmp, true
+				}
+			}
+		}
+		for i := range c.Sub {
+			if c.Sub[i].ID == id {
+				c = &c.Sub[i]
+				continue next
+			}
+		}
+		return nil, nil, false
+	}
+	return parents, c, true
+}
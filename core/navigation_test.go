@@ -0,0 +1,90 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func navigationTree() *Category {
+	return &Category{ID: "root", Sub: []Category{
+		{ID: "guides", Sub: []Category{
+			{ID: "setup", Components: []Component{
+				&Segment{ID: "intro", Index: 1},
+				&Segment{ID: "install", Index: 2},
+				&Picture{ID: "screen.png"},
+			}},
+			{ID: "usage"},
+		}},
+		{ID: "news", Components: []Component{&Segment{ID: "first"}}},
+	}}
+}
+
+func TestFind(t *testing.T) {
+	c := navigationTree()
+	testCases := map[string]string{
+		"guides/setup/intro":      "intro",
+		"/guides/setup/":          "setup",
+		"guides/setup/screen.png": "screen.png",
+		"":                        "root",
+		"guides/missing":          "",
+		"guides/setup/intro/x":    "",
+	}
+	for p, id := range testCases {
+		cmp, ok := c.Find(p)
+		if ok != (id != "") {
+			t.Fatalf("%q: expected found %v, got %v", p, id != "", ok)
+		}
+		if ok && cmp.GetID() != id {
+			t.Fatalf("%q: expected %q, got %q", p, id, cmp.GetID())
+		}
+	}
+}
+
+func TestBreadcrumbsSiblings(t *testing.T) {
+	c := navigationTree()
+	parents, ok := c.Breadcrumbs("guides/setup/install")
+	if !ok {
+		t.Fatal("Expected breadcrumbs")
+	}
+	var ids []string
+	for _, p := range parents {
+		ids = append(ids, p.ID)
+	}
+	if exp := []string{"root", "guides", "setup"}; !reflect.DeepEqual(ids, exp) {
+		t.Fatalf("Expected %v, got %v", exp, ids)
+	}
+
+	prev, next, ok := c.Siblings("guides/setup/install")
+	if !ok || prev.GetID() != "intro" || next.GetID() != "screen.png" {
+		t.Fatalf("Unexpected siblings %v, %v", prev, next)
+	}
+	prev, next, ok = c.Siblings("guides/usage")
+	if !ok || prev.GetID() != "setup" || next != nil {
+		t.Fatalf("Unexpected siblings %v, %v", prev, next)
+	}
+	if _, _, ok := c.Siblings(""); ok {
+		t.Fatal("Expected no siblings for root")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	c := navigationTree()
+	var paths []string
+	err := c.Walk(func(p string, parents []*Category, cmp Component) error {
+		if len(parents) == 0 || parents[0] != c {
+			t.Fatalf("%s: unexpected parents %v", p, parents)
+		}
+		paths = append(paths, p)
+		if p == "guides/setup" {
+			return SkipCategory
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{"guides", "guides/setup", "guides/usage", "news", "news/first"}
+	if !reflect.DeepEqual(paths, exp) {
+		t.Fatalf("Expected %v, got %v", exp, paths)
+	}
+}
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// WordsPerMinute is the reading speed used by ReadingTime.
+const WordsPerMinute = 200
+
+// Heading is an entry of a Segment outline.
+type Heading struct {
+	Level  int
+	Title  string
+	Anchor string
+	Sub    []Heading
+}
+
+// Outline returns the headings of the Body, nested by level. Anchors are the
+// same as the IDs of the rendered headings.
+func (s *Segment) Outline() []Heading {
+	var (
+		source = s.Body
+		doc    = parseMarkdown(source)
+		root   = Heading{Level: 0}
+		stack  = []*Heading{&root}
+	)
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		h, ok := n.(*ast.Heading)
+		if !ok {
+			continue
+		}
+		id, _ := h.AttributeString("id")
+		anchor, _ := id.([]byte)
+		for len(stack) > 1 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.Sub = append(parent.Sub, Heading{
+			Level:  h.Level,
+			Title:  string(h.Text(source)),
+			Anchor: string(anchor),
+		})
+		stack = append(stack, &parent.Sub[len(parent.Sub)-1])
+	}
+	return root.Sub
+}
+
+// WordCount returns the number of words in the Body text, code blocks excluded.
+func (s *Segment) WordCount() int {
+	var (
+		b   bytes.Buffer
+		doc = parseMarkdown(s.Body)
+	)
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.CodeBlock, *ast.FencedCodeBlock, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			b.Write(n.Segment.Value(s.Body))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		default:
+			if n.Type() == ast.TypeBlock {
+				b.WriteByte(' ')
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return len(strings.Fields(b.String()))
+}
+
+// ReadingTime returns the time needed to read the Body, rounded up to minutes.
+func (s *Segment) ReadingTime() time.Duration {
+	minutes := math.Ceil(float64(s.WordCount()) / WordsPerMinute)
+	return time.Duration(minutes) * time.Minute
+}
+
+// TOCEntry is an element of a table of contents.
+type TOCEntry struct {
+	// Path is the location in the tree.
+	Path string
+	// Title is the "title" Meta, or the ID.
+	Title string
+	// Headings is the outline of a Segment.
+	Headings []Heading
+	// Sub contains the elements of a Category.
+	Sub []TOCEntry
+}
+
+// TOC returns the table of contents of the Category, made of Segments and
+// sub-categories in order.
+func (c *Category) TOC() []TOCEntry {
+	return c.toc("")
+}
+
+func (c *Category) toc(prefix string) []TOCEntry {
+	var entries []TOCEntry
+	for _, cmp := range c.Components {
+		s, ok := cmp.(*Segment)
+		if !ok {
+			continue
+		}
+		entries = append(entries, TOCEntry{
+			Path:     path.Join(prefix, s.ID),
+			Title:    title(s.Meta, s.ID),
+			Headings: s.Outline(),
+		})
+	}
+	for i := range c.Sub {
+		sub := &c.Sub[i]
+		p := path.Join(prefix, sub.ID)
+		entries = append(entries, TOCEntry{Path: p, Title: title(sub.Meta, sub.ID), Sub: sub.toc(p)})
+	}
+	return entries
+}
+
+func title(m Meta, id string) string {
+	if t := m.String("title"); t != "" {
+		return t
+	}
+	return id
+}
+
+// md parses the Segment bodies with the extensions used for rendering.
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.Table, extension.Footnote),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// parseMarkdown parses the markdown with the extensions used for rendering.
+func parseMarkdown(source []byte) ast.Node {
+	ctx := parser.NewContext(parser.WithIDs(make(Anchors)))
+	return md.Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+}
+
+// Anchors generates unique heading anchors, adding a number to duplicates.
+// It implements the goldmark parser.IDs interface.
+type Anchors map[string]bool
+
+// Generate returns the anchor for a heading.
+func (a Anchors) Generate(value []byte, _ ast.NodeKind) []byte {
+	id := Anchor(string(value))
+	if id == "" {
+		id = "section"
+	}
+	for i, base := 1, id; a[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	a[id] = true
+	return []byte(id)
+}
+
+// Put marks an anchor as used.
+func (a Anchors) Put(value []byte) { a[string(value)] = true }
+
+// Anchor returns the slug of a heading: lower case letters and digits, with
+// dashes replacing spaces and punctuation.
+func Anchor(heading string) string {
+	var (
+		b    strings.Builder
+		dash bool
+	)
+	for _, r := range strings.TrimSpace(heading) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && b.Len() != 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
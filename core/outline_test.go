@@ -0,0 +1,65 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOutline(t *testing.T) {
+	s := Segment{Body: []byte("# Intro\n\nSome *nice* words\nhere.\n\n## Setup\n\n### Linux\n\n```\nnot counted\n```\n\n## Setup\n\n# Usage `tent`\n")}
+	exp := []Heading{
+		{Level: 1, Title: "Intro", Anchor: "intro", Sub: []Heading{
+			{Level: 2, Title: "Setup", Anchor: "setup", Sub: []Heading{
+				{Level: 3, Title: "Linux", Anchor: "linux"},
+			}},
+			{Level: 2, Title: "Setup", Anchor: "setup-1"},
+		}},
+		{Level: 1, Title: "Usage tent", Anchor: "usage-tent"},
+	}
+	if got := s.Outline(); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("Expected %v, got %v", exp, got)
+	}
+	if got := s.WordCount(); got != 10 {
+		t.Fatalf("Expected %v, got %v", 10, got)
+	}
+	if got := s.ReadingTime(); got != time.Minute {
+		t.Fatalf("Expected %v, got %v", time.Minute, got)
+	}
+	if got := (&Segment{}).ReadingTime(); got != 0 {
+		t.Fatalf("Expected %v, got %v", 0, got)
+	}
+}
+
+func TestTOC(t *testing.T) {
+	c := Category{
+		Components: []Component{
+			&Segment{ID: "b", Index: 2, Body: []byte("# B\n")},
+			&Segment{ID: "a", Index: 1, Meta: Meta{"title": "First"}},
+			&Picture{ID: "p"},
+		},
+		Sub: []Category{{ID: "x", Meta: Meta{"title": "Chapter"}, Components: []Component{&Segment{ID: "c"}}}},
+	}
+	c.sort()
+	exp := []TOCEntry{
+		{Path: "a", Title: "First"},
+		{Path: "b", Title: "b", Headings: []Heading{{Level: 1, Title: "B", Anchor: "b"}}},
+		{Path: "x", Title: "Chapter", Sub: []TOCEntry{{Path: "x/c", Title: "c"}}},
+	}
+	if got := c.TOC(); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("Expected %v, got %v", exp, got)
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	for in, exp := range map[string]string{
+		"Hello, World!":    "hello-world",
+		"  **Über** Café ": "über-café",
+		"v1.2 release":     "v1-2-release",
+		"!!!":              "",
+	} {
+		if got := Anchor(in); got != exp {
+			t.Fatalf("%q: expected %q, got %q", in, exp, got)
+		}
+	}
+}
@@ -24,6 +24,9 @@ func (p *Picture) MarshalJSON() ([]byte, error) {
 // GetID implements the Component interface.
 func (p *Picture) GetID() string { return p.ID }
 
+// SetID implements the Renamer interface.
+func (p *Picture) SetID(id string) { p.ID = id }
+
 // Encode returns Item contents.
 func (p *Picture) Encode() ([]byte, error) {
 	return p.Data, nil
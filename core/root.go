@@ -2,9 +2,13 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"path"
+	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/go-tent/tent/item"
@@ -28,121 +32,529 @@ type Component interface {
 // Components is a list of the available Components.
 var Components = []Component{new(Segment), new(Picture), new(Checks), new(Form)}
 
+// Renamer is a Component that can change its ID.
+type Renamer interface {
+	SetID(string)
+}
+
+// Indexer is a Component that can change its Order.
+type Indexer interface {
+	SetIndex(float64)
+}
+
+// NewItem returns the Item for the Component, prefix is the path of its Category.
 func NewItem(prefix []string, cmp Component) (item.Item, error) {
+	return newItem(prefix, cmp)
+}
+
+// ItemName returns the Item name for the Component, prefix is the path of its Category.
+func ItemName(prefix []string, cmp Component) string {
+	return itemName(prefix, cmp)
+}
+
+func newItem(prefix []string, cmp Component) (item.Memory, error) {
+	b, err := cmp.Encode()
+	if err != nil {
+		return item.Memory{}, err
+	}
+	return item.Memory{ID: itemName(prefix, cmp), Contents: b}, nil
+}
+
+// itemName returns the Item name for the Component, prefix is the path of its Category.
+func itemName(prefix []string, cmp Component) string {
 	dir := path.Join(prefix...)
-	if cat, ok := cmp.(*Category); ok {
-		b, err := cat.Encode()
-		if err != nil {
-			return nil, err
-		}
-		return item.Memory{ID: path.Join(dir, ".category.yml"), Contents: b}, nil
+	if _, ok := cmp.(*Category); ok {
+		return path.Join(dir, ".category.yml")
 	}
 	name := cmp.GetID()
 	if pre, exts := cmp.Format(); len(exts) == 1 {
 		name = pre + name + exts[0]
 	}
-	b, err := cmp.Encode()
-	if err != nil {
-		return nil, err
-	}
-	return item.Memory{ID: path.Join(dir, name), Contents: b}, nil
+	return path.Join(dir, name)
 }
 
-// NewRoot returns a new Root.
+// UnknownPolicy is how a Root handles Items with no matching Component.
+type UnknownPolicy int
+
+// Available UnknownPolicies.
+const (
+	// UnknownSkip ignores the Item.
+	UnknownSkip UnknownPolicy = iota
+	// UnknownError fails decoding.
+	UnknownError
+	// UnknownAttach decodes the Item as an Attachment.
+	UnknownAttach
+)
+
+// SlugPattern allows lowercase IDs of letters and numbers, separated by "-", "_" or ".".
+var SlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:[-_.][a-z0-9]+)*$`)
+
+// Options are the Root settings.
+type Options struct {
+	Unknown UnknownPolicy
+	// IDPattern, if not nil, must be matched by all the IDs (example: SlugPattern).
+	IDPattern *regexp.Regexp
+	// NormalizeFrontMatter encodes all Segments with YAML front matter.
+	NormalizeFrontMatter bool
+}
+
+// NewRoot returns a new Root with default Options.
 func NewRoot(components ...Component) (*Root, error) {
+	return NewRootOptions(Options{}, components...)
+}
+
+// NewRootOptions returns a new Root with the given Options.
+func NewRootOptions(opts Options, components ...Component) (*Root, error) {
 	if err := detectCollisions(components); err != nil {
 		return nil, err
 	}
-	return &Root{Category: new(Category), decoders: components}, nil
+	return &Root{Category: new(Category), decoders: components, opts: opts}, nil
 }
 
 // Root is a container for a component Tree.
 type Root struct {
 	*Category
 	decoders []Component
+	opts     Options
+	origins  map[string]origin
+}
+
+// origin is a decoded Item: its contents, if they are not kept by the
+// Component, and the Schema defaults that were set.
+type origin struct {
+	raw      []byte
+	defaults Meta
 }
 
 // IsValid verifies the existence of an Item Component.
 func (r *Root) IsValid(i item.Item) error {
 	_, file := path.Split(i.Name())
-	if file == ".category.yml" {
-		_, err := r.decodeCategory(i)
+	switch file {
+	case ".category.yml":
+		if _, err := r.decodeCategory(i); err != nil {
+			return err
+		}
+		return nil
+	case ".schema.yml":
+		if _, err := r.decodeSchema(i); err != nil {
+			return err
+		}
+		return nil
+	}
+	if _, err := r.decodeComponent(i); err != nil {
 		return err
 	}
-	cmp, err := r.decodeComponent(i)
+	return nil
+}
+
+// Decode trasforms a Source in a Category tree, stopping at the first error.
+func (r *Root) Decode(src source.Source) error {
+	d, err := r.decodeSource(src, false)
 	if err != nil {
 		return err
 	}
-	if cmp == nil {
-		return fmt.Errorf("No parser for %s", path.Base(i.Name()))
+	r.Category = &d.root
+	r.origins = d.origins
+	return nil
+}
+
+// Validate decodes the whole Source without changing the tree, returning all
+// the errors found as Errors.
+func (r *Root) Validate(src source.Source) error {
+	d, err := r.decodeSource(src, true)
+	if err != nil {
+		return err
+	}
+	if len(d.errs) != 0 {
+		return d.errs
 	}
 	return nil
 }
 
-// Decode trasforms a Source in a Category tree.
-func (r *Root) Decode(src source.Source) error {
-	root := Category{ID: "root"}
+// decoding is the result of a Source decoding.
+type decoding struct {
+	root    Category
+	origins map[string]origin
+	errs    Errors
+	// defined contains the categories with a .category.yml
+	defined map[string]bool
+}
+
+// decodeSource decodes all Items, collecting errors if all is true.
+func (r *Root) decodeSource(src source.Source, all bool) (*decoding, error) {
+	d := decoding{
+		root:    Category{ID: "root"},
+		origins: make(map[string]origin),
+		defined: make(map[string]bool),
+	}
 	for i, err := src.Next(); i != nil; i, err = src.Next() {
+		if err != nil {
+			return nil, err
+		}
+		if err := r.decodeItem(&d, i); err != nil {
+			if !all {
+				return nil, err
+			}
+			d.errs = append(d.errs, err)
+		}
+	}
+	for _, err := range r.checkIDs(nil, &d.root) {
+		if !all {
+			return nil, err
+		}
+		d.errs = append(d.errs, err)
+	}
+	for _, err := range d.checkSchemas(nil, &d.root, nil, nil) {
+		if !all {
+			return nil, err
+		}
+		d.errs = append(d.errs, err)
+	}
+	d.root.sort()
+	return &d, nil
+}
+
+// checkSchemas validates the Meta of the Components against the Schema of
+// their Category and its parents, with the Meta inherited from the Categories,
+// setting the default values.
+func (d *decoding) checkSchemas(prefix []string, c *Category, s *Schema, inherited EffectiveMeta) Errors {
+	var errs Errors
+	s = s.inherit(c.Schema)
+	e := inherited.with(c.Meta, path.Join(prefix...))
+	if s != nil {
+		for _, cmp := range c.Components {
+			name := itemName(prefix, cmp)
+			list, defaults := applySchema(s, cmp, e)
+			for _, err := range list {
+				errs = append(errs, newDecodeError(name, cmp, err))
+			}
+			if o, ok := d.origins[name]; ok && len(defaults) != 0 {
+				o.defaults = defaults
+				d.origins[name] = o
+			}
+		}
+	}
+	for i := range c.Sub {
+		p := append(prefix[:len(prefix):len(prefix)], c.Sub[i].ID)
+		errs = append(errs, d.checkSchemas(p, &c.Sub[i], s, e)...)
+	}
+	return errs
+}
+
+// checkIDs verifies that IDs match the IDPattern and are unique in each
+// Category, even ignoring the case. Components and sub-categories are checked
+// separately, as they can share an ID.
+func (r *Root) checkIDs(prefix []string, c *Category) Errors {
+	var errs Errors
+	check := func(seen map[string]struct{ id, name string }, cmp Component, name string) {
+		id := cmp.GetID()
+		if p := r.opts.IDPattern; p != nil && !p.MatchString(id) {
+			errs = append(errs, newDecodeError(name, cmp, fmt.Errorf("invalid ID %q, must match %s", id, p)))
+		}
+		key := strings.ToLower(id)
+		other, ok := seen[key]
+		switch {
+		case !ok:
+			seen[key] = struct{ id, name string }{id, name}
+		case other.id == id:
+			errs = append(errs, newDecodeError(name, cmp, fmt.Errorf("duplicate ID %q, used by %s", id, other.name)))
+		default:
+			errs = append(errs, newDecodeError(name, cmp, fmt.Errorf("ID %q differs only in case from %s", id, other.name)))
+		}
+	}
+	seen := make(map[string]struct{ id, name string })
+	for _, cmp := range c.Components {
+		check(seen, cmp, itemName(prefix, cmp))
+	}
+	seen = make(map[string]struct{ id, name string })
+	for i := range c.Sub {
+		p := append(prefix[:len(prefix):len(prefix)], c.Sub[i].ID)
+		check(seen, &c.Sub[i], path.Join(p...)+"/")
+	}
+	for i := range c.Sub {
+		p := append(prefix[:len(prefix):len(prefix)], c.Sub[i].ID)
+		errs = append(errs, r.checkIDs(p, &c.Sub[i])...)
+	}
+	return errs
+}
+
+// decodeItem adds the Item to the tree.
+func (r *Root) decodeItem(d *decoding, i item.Item) *DecodeError {
+	var (
+		name      = i.Name()
+		dir, file = path.Split(name)
+		rec       = &recorder{Item: i}
+		src       = i
+	)
+	if p, _ := r.decoder(file); file == ".category.yml" || file == ".schema.yml" || p != nil && !verbatim(p) {
+		src = rec
+	}
+	if file == ".category.yml" {
+		cat, err := r.decodeCategory(src)
 		if err != nil {
 			return err
 		}
-		name := i.Name()
-		dir, file := path.Split(name)
-		if file == ".category.yml" {
-			cat, err := r.decodeCategory(i)
-			if err != nil {
-				return err
+		dir = path.Clean(dir)
+		node := d.root.ensure(dir)
+		if d.defined[dir] {
+			if node.Index != cat.Index || node.Sort != cat.Sort || !reflect.DeepEqual(node.Explicit, cat.Explicit) ||
+				!reflect.DeepEqual(node.Meta, cat.Meta) {
+				return newDecodeError(name, cat, fmt.Errorf("conflicting definition of category %q", dir))
 			}
-			parent := root.ensure(path.Dir(path.Clean(dir)))
-			parent.Sub = append(parent.Sub, *cat)
-			continue
+			return nil
 		}
-		cmp, err := r.decodeComponent(i)
+		d.defined[dir] = true
+		node.Index, node.Sort, node.Explicit, node.Meta, node.src = cat.Index, cat.Sort, cat.Explicit, cat.Meta, cat.src
+		d.origins[name] = origin{raw: rec.buf.Bytes()}
+		return nil
+	}
+	if file == ".schema.yml" {
+		s, err := r.decodeSchema(src)
+		if err != nil {
+			return err
+		}
+		d.root.ensure(path.Clean(dir)).Schema = s
+		d.origins[name] = origin{raw: rec.buf.Bytes()}
+		return nil
+	}
+	cmp, derr := r.decodeComponent(src)
+	if derr != nil {
+		return derr
+	}
+	if cmp == nil {
+		return nil
+	}
+	parent := d.root.ensure(dir)
+	parent.Components = append(parent.Components, cmp)
+	d.origins[name] = origin{raw: rec.buf.Bytes()}
+	return nil
+}
+
+// verbatim tells if the Component is encoded back to the decoded contents, so
+// there is no need to keep them.
+func verbatim(cmp Component) bool {
+	switch cmp.(type) {
+	case *Picture, *Attachment:
+		return true
+	}
+	return false
+}
+
+// recorder is an Item that keeps a copy of the contents read.
+type recorder struct {
+	item.Item
+	buf bytes.Buffer
+}
+
+// Content implements the item.Item interface.
+func (r *recorder) Content() (io.ReadCloser, error) {
+	rc, err := r.Item.Content()
+	if err != nil {
+		return nil, err
+	}
+	return &recordCloser{Reader: io.TeeReader(rc, &r.buf), rc: rc}, nil
+}
+
+// recordCloser reads the rest of the contents before closing.
+type recordCloser struct {
+	io.Reader
+	rc io.Closer
+}
+
+func (r *recordCloser) Close() error {
+	if _, err := io.Copy(ioutil.Discard, r.Reader); err != nil {
+		r.rc.Close()
+		return err
+	}
+	return r.rc.Close()
+}
+
+// pristine returns the encoding of the Item as it was decoded, with the
+// defaults of the Schema.
+func (r *Root) pristine(name string, o origin) ([]byte, error) {
+	var (
+		i       = item.Memory{ID: name, Contents: o.raw}
+		_, file = path.Split(name)
+		cmp     Component
+		err     *DecodeError
+	)
+	switch file {
+	case ".category.yml":
+		cmp, err = r.decodeCategory(i)
+	case ".schema.yml":
+		s, err := r.decodeSchema(i)
 		if err != nil {
+			return nil, err
+		}
+		return s.Encode()
+	default:
+		cmp, err = r.decodeComponent(i)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cmp == nil {
+		return nil, fmt.Errorf("No parser for %s", file)
+	}
+	setDefaults(cmp, o.defaults)
+	return cmp.Encode()
+}
+
+// original returns the decoded contents of the Item, if b is the same as
+// their encoding.
+func (r *Root) original(name string, b []byte) []byte {
+	o, ok := r.origins[name]
+	if !ok || o.raw == nil {
+		return b
+	}
+	if p, err := r.pristine(name, o); err != nil || !bytes.Equal(p, b) {
+		return b
+	}
+	return o.raw
+}
+
+// Encode trasforms the Category tree in a Source. Components that did not
+// change since Decode keep their original contents.
+func (r *Root) Encode() (source.Source, error) {
+	var items []item.Memory
+	if r.hasFile(nil, r.Category) {
+		if err := r.encodeItem(nil, r.Category, &items); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.encode(nil, r.Category, &items); err != nil {
+		return nil, err
+	}
+	return &source.Memory{Items: items}, nil
+}
+
+func (r *Root) encode(prefix []string, c *Category, items *[]item.Memory) error {
+	if c.Schema != nil {
+		if err := r.encodeSchema(prefix, c.Schema, items); err != nil {
+			return err
+		}
+	}
+	for _, cmp := range c.Components {
+		if err := r.encodeItem(prefix, cmp, items); err != nil {
+			return err
+		}
+	}
+	for i := range c.Sub {
+		sub := &c.Sub[i]
+		p := append(prefix[:len(prefix):len(prefix)], sub.ID)
+		if r.hasFile(p, sub) {
+			if err := r.encodeItem(p, sub, items); err != nil {
+				return err
+			}
+		}
+		if err := r.encode(p, sub, items); err != nil {
 			return err
 		}
-		parent := root.ensure(dir)
-		parent.Components = append(parent.Components, cmp)
 	}
-	root.sort()
-	r.Category = &root
 	return nil
 }
 
-func (r *Root) decodeCategory(i item.Item) (*Category, error) {
+// hasFile tells if the Category needs a .category.yml file.
+func (r *Root) hasFile(prefix []string, c *Category) bool {
+	_, ok := r.origins[path.Join(path.Join(prefix...), ".category.yml")]
+	return ok || c.Index != 0 || c.Sort != "" || len(c.Explicit) != 0 || len(c.Meta) != 0
+}
+
+func (r *Root) encodeItem(prefix []string, cmp Component, items *[]item.Memory) error {
+	if s, ok := cmp.(*Segment); ok && r.opts.NormalizeFrontMatter && s.FrontMatter != YAMLFrontMatter {
+		n := *s
+		n.FrontMatter, n.src = YAMLFrontMatter, nil
+		cmp = &n
+	}
+	m, err := newItem(prefix, cmp)
+	if err != nil {
+		return fmt.Errorf("%s: %s", path.Join(prefix...), err)
+	}
+	m.Contents = r.original(m.ID, m.Contents)
+	*items = append(*items, m)
+	return nil
+}
+
+func (r *Root) encodeSchema(prefix []string, s *Schema, items *[]item.Memory) error {
+	name := schemaName(prefix)
+	b, err := s.Encode()
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+	*items = append(*items, item.Memory{ID: name, Contents: r.original(name, b)})
+	return nil
+}
+
+func (r *Root) decodeCategory(i item.Item) (*Category, *DecodeError) {
 	dir, _ := path.Split(i.Name())
 	contents, err := i.Content()
 	if err != nil {
-		return nil, fmt.Errorf("%s: %s", dir, err)
+		return nil, newDecodeError(i.Name(), (*Category)(nil), err)
 	}
 	defer contents.Close()
 
 	cat, err := (*Category).decode(nil, path.Base(dir), contents)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %s", dir, err)
+		return nil, newDecodeError(i.Name(), (*Category)(nil), err)
 	}
 	return cat, nil
 }
 
-func (r *Root) decodeComponent(i item.Item) (Component, error) {
+func (r *Root) decodeSchema(i item.Item) (*Schema, *DecodeError) {
+	contents, err := i.Content()
+	if err != nil {
+		return nil, newDecodeError(i.Name(), nil, err)
+	}
+	defer contents.Close()
+
+	s, err := decodeSchema(contents)
+	if err != nil {
+		return nil, newDecodeError(i.Name(), nil, err)
+	}
+	return s, nil
+}
+
+func (r *Root) decodeComponent(i item.Item) (Component, *DecodeError) {
 	_, file := path.Split(i.Name())
-	for _, p := range r.decoders {
-		name := r.matchDecoder(p, file)
-		if name == "" {
-			continue
-		}
+	if p, name := r.decoder(file); p != nil {
 		r, err := i.Content()
 		if err != nil {
-			return nil, err
+			return nil, newDecodeError(i.Name(), p, err)
 		}
 		defer r.Close()
 		cmp, err := p.Decode(name, r)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %s", i.Name(), err)
+			return nil, newDecodeError(i.Name(), p, err)
+		}
+		return cmp, nil
+	}
+	switch r.opts.Unknown {
+	case UnknownSkip:
+		return nil, nil
+	case UnknownAttach:
+		r, err := i.Content()
+		if err != nil {
+			return nil, newDecodeError(i.Name(), (*Attachment)(nil), err)
+		}
+		defer r.Close()
+		cmp, err := (*Attachment).decode(nil, file, r)
+		if err != nil {
+			return nil, newDecodeError(i.Name(), cmp, err)
 		}
 		return cmp, nil
+	default:
+		return nil, newDecodeError(i.Name(), nil, fmt.Errorf("No parser for %s", file))
+	}
+}
+
+// decoder returns the Component that decodes the file and the name to use.
+func (r *Root) decoder(file string) (Component, string) {
+	for _, p := range r.decoders {
+		if name := r.matchDecoder(p, file); name != "" {
+			return p, name
+		}
 	}
-	return nil, nil
+	return nil, ""
 }
 
 func (r *Root) matchDecoder(p Component, name string) string {
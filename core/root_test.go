@@ -3,6 +3,7 @@ package core
 import (
 	"io"
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/go-tent/tent/item"
@@ -123,3 +124,209 @@ func (mockCmp) Decode(id string, r io.Reader) (Component, error) {
 	}
 	return m, nil
 }
+
+func TestEncode(t *testing.T) {
+	items := []item.Memory{
+		{ID: "a/.category.yml", Contents: []byte("# comment\ntitle: A\nindex: 2\n")},
+		{ID: "a/s_one.md", Contents: []byte("---\ntitle:   one\nindex: 1\n---\nbody one")},
+		{ID: "a/s_two.md", Contents: []byte("---\ntitle: two\nindex: 2\n---\nbody two")},
+		{ID: "a/b/pic.png", Contents: []byte("png")},
+	}
+	r, err := NewRoot(Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Decode(&source.Memory{Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	if o := r.origins["a/b/pic.png"]; o.raw != nil {
+		t.Fatalf("Expected no raw contents for pictures, got %q", o.raw)
+	}
+	r.Sub[0].Components[1].(*Segment).Body = []byte("changed")
+
+	src, err := r.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for i, err := src.Next(); i != nil; i, err = src.Next() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[i.Name()] = string(i.(item.Memory).Contents)
+	}
+	if l := len(got); l != len(items) {
+		t.Fatalf("Expected %d items, got %v", len(items), got)
+	}
+	for _, i := range items {
+		c, ok := got[i.ID]
+		if !ok {
+			t.Fatalf("Expected %q, got %v", i.ID, got)
+		}
+		if i.ID == "a/s_two.md" {
+			if exp := "---\ntitle: two\nindex: 2\n---\nchanged"; c != exp {
+				t.Fatalf("Expected %q, got %q", exp, c)
+			}
+			continue
+		}
+		if c != string(i.Contents) {
+			t.Fatalf("%s: expected %q, got %q", i.ID, i.Contents, c)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	items := []item.Memory{
+		{ID: "a/.category.yml", Contents: []byte("index: [1]")},
+		{ID: "a/s_ok.md", Contents: []byte("---\ntitle: ok\n---\nbody")},
+		{ID: "a/s_header.md", Contents: []byte("title: no header")},
+		{ID: "a/s_yaml.md", Contents: []byte("---\ntitle: x\nindex: abc\n---\nbody")},
+		{ID: "a/c_list.yml", Contents: []byte("list:\n  - check: a\n   bad: indent")},
+		{ID: "a/file.zip", Contents: []byte("zip")},
+	}
+	r, err := NewRootOptions(Options{Unknown: UnknownError}, Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Validate(&source.Memory{Items: items})
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("Expected %T, got %v", errs, err)
+	}
+	exp := []DecodeError{
+		{Path: "a/.category.yml", Type: "Category", Line: 1},
+		{Path: "a/s_header.md", Type: "Segment", Line: 1},
+		{Path: "a/s_yaml.md", Type: "Segment", Line: 3},
+		{Path: "a/c_list.yml", Type: "Checks", Line: 2},
+		{Path: "a/file.zip"},
+	}
+	if len(errs) != len(exp) {
+		t.Fatalf("Expected %d errors, got %d:\n%s", len(exp), len(errs), errs)
+	}
+	for i, e := range exp {
+		if got := errs[i]; got.Path != e.Path || got.Type != e.Type || got.Line != e.Line {
+			t.Errorf("Expected %s %s line %d, got %s", e.Path, e.Type, e.Line, got)
+		}
+	}
+	if r.Category.ID != "" {
+		t.Fatalf("Expected tree to be unchanged, got %s", r.Category)
+	}
+}
+
+func TestDecodeUnknown(t *testing.T) {
+	items := []item.Memory{
+		{ID: "a/s_x.md", Contents: []byte("---\nindex: 1\n---\n")},
+		{ID: "a/doc.pdf", Contents: []byte("pdf")},
+	}
+	testCases := map[UnknownPolicy]int{UnknownError: -1, UnknownSkip: 1, UnknownAttach: 2}
+	for policy, count := range testCases {
+		r, err := NewRootOptions(Options{Unknown: policy}, Components...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.Decode(&source.Memory{Items: items})
+		if count == -1 {
+			if err == nil {
+				t.Fatalf("[%d] Expected error", policy)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%d] %s", policy, err)
+		}
+		if l := len(r.Sub[0].Components); l != count {
+			t.Fatalf("[%d] Expected %d components, got %d", policy, count, l)
+		}
+		if policy != UnknownAttach {
+			continue
+		}
+		a, ok := r.Sub[0].Components[1].(*Attachment)
+		if !ok || a.ID != "doc.pdf" || string(a.Data) != "pdf" {
+			t.Fatalf("[%d] Expected attachment, got %v", policy, r.Sub[0].Components[1])
+		}
+		src, err := r.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found bool
+		for i, _ := src.Next(); i != nil; i, _ = src.Next() {
+			if m := i.(item.Memory); m.ID == "a/doc.pdf" && string(m.Contents) == "pdf" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("[%d] Expected attachment to be encoded", policy)
+		}
+	}
+}
+
+func TestDecodeCategoryOrder(t *testing.T) {
+	items := []item.Memory{
+		{ID: "a/b/m_x.mock", Contents: []byte("index: 1")},
+		{ID: "a/b/.category.yml", Contents: []byte("index: 2\ntitle: B")},
+		{ID: "a/.category.yml", Contents: []byte("index: 1\ntitle: A")},
+		{ID: ".category.yml", Contents: []byte("title: Root")},
+	}
+	r, err := NewRoot(mockCmp{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Decode(&source.Memory{Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	if l := len(r.Sub); l != 1 {
+		t.Fatalf("Expected %d category, got %d", 1, l)
+	}
+	if l := len(r.Sub[0].Sub); l != 1 {
+		t.Fatalf("Expected %d category, got %d", 1, l)
+	}
+	b := r.Sub[0].Sub[0]
+	if b.Index != 2 || b.Meta["title"] != "B" || len(b.Components) != 1 {
+		t.Fatalf("Unexpected category %s, components %v", &b, b.Components)
+	}
+	if title := r.Meta["title"]; title != "Root" {
+		t.Fatalf("Expected %q root title, got %q", "Root", title)
+	}
+
+	items = append(items, item.Memory{ID: "a/b/.category.yml", Contents: []byte("index: 3\ntitle: B")})
+	err = r.Decode(&source.Memory{Items: items})
+	if e, ok := err.(*DecodeError); !ok || e.Path != "a/b/.category.yml" {
+		t.Fatalf("Expected conflict error, got %v", err)
+	}
+}
+
+func TestCheckIDs(t *testing.T) {
+	items := []item.Memory{
+		{ID: "a/s_intro.md", Contents: []byte("---\nindex: 1\n---\n")},
+		{ID: "a/c_intro.yml", Contents: []byte("index: 2")},
+		{ID: "a/f_Intro.yml", Contents: []byte("index: 3")},
+		{ID: "a/intro/s_x.md", Contents: []byte("---\nindex: 1\n---\n")},
+		{ID: "a/Intro/s_y.md", Contents: []byte("---\nindex: 1\n---\n")},
+		{ID: "a/s_Bad Name.md", Contents: []byte("---\nindex: 1\n---\n")},
+		{ID: "b/s_intro.md", Contents: []byte("---\nindex: 1\n---\n")},
+	}
+	r, err := NewRootOptions(Options{IDPattern: SlugPattern}, Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, ok := r.Validate(&source.Memory{Items: items}).(Errors)
+	if !ok {
+		t.Fatalf("Expected %T, got %v", errs, err)
+	}
+	exp := []string{
+		`a/c_intro.yml: Checks: duplicate ID "intro", used by a/s_intro.md`,
+		`a/f_Intro.yml: Form: invalid ID "Intro", must match`,
+		`a/f_Intro.yml: Form: ID "Intro" differs only in case from a/s_intro.md`,
+		`a/s_Bad Name.md: Segment: invalid ID "Bad Name", must match`,
+		`a/Intro/: Category: invalid ID "Intro", must match`,
+		`a/Intro/: Category: ID "Intro" differs only in case from a/intro/`,
+	}
+	if len(errs) != len(exp) {
+		t.Fatalf("Expected %d errors, got %d:\n%s", len(exp), len(errs), errs)
+	}
+	for i := range exp {
+		if !strings.HasPrefix(errs[i].Error(), exp[i]) {
+			t.Errorf("Expected %q, got %q", exp[i], errs[i])
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Schema declares the Meta fields of the Segments, Checks and Forms of a
+// Category and its sub-categories, it's decoded from a .schema.yml file.
+type Schema struct {
+	// Strict makes the keys missing in Fields an error.
+	Strict bool `yaml:"strict,omitempty"`
+	// Fields are the known keys.
+	Fields map[string]Field `yaml:"fields,omitempty"`
+
+	// src is the decoded YAML, Encode patches it.
+	src []byte
+}
+
+// Field describes a Meta value.
+type Field struct {
+	// Type is one of string, number, bool, date, list, map, any if empty.
+	Type     string        `yaml:"type,omitempty"`
+	Required bool          `yaml:"required,omitempty"`
+	Enum     []interface{} `yaml:"enum,omitempty"`
+	// Pattern is a regular expression that scalar values must match.
+	Pattern string `yaml:"pattern,omitempty"`
+	// Default is set when the key is missing and not inherited.
+	Default interface{} `yaml:"default,omitempty"`
+	// Inherit lets a value inherited from the Categories satisfy Required.
+	Inherit bool `yaml:"inherit,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Encode returns Item contents.
+func (s *Schema) Encode() ([]byte, error) {
+	return encodeYAML(s.src, s)
+}
+
+func decodeSchema(r io.Reader) (*Schema, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s := Schema{src: src}
+	if err := yaml.UnmarshalStrict(src, &s); err != nil {
+		return nil, yamlError(err, 0)
+	}
+	for _, k := range s.keys() {
+		f := s.Fields[k]
+		if err := f.compile(); err != nil {
+			return nil, fmt.Errorf("field %q: %s", k, err)
+		}
+		s.Fields[k] = f
+	}
+	return &s, nil
+}
+
+// compile verifies the Field definition.
+func (f *Field) compile() error {
+	switch f.Type {
+	case "", "string", "number", "bool", "date", "list", "map":
+	default:
+		return fmt.Errorf("unknown type %q", f.Type)
+	}
+	if f.Pattern != "" {
+		p, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return err
+		}
+		f.pattern = p
+	}
+	if f.Default != nil {
+		if err := f.check(f.Default, false); err != nil {
+			return fmt.Errorf("default: %s", err)
+		}
+	}
+	return nil
+}
+
+// keys returns the sorted Field names.
+func (s *Schema) keys() []string {
+	keys := make([]string, 0, len(s.Fields))
+	for k := range s.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// inherit returns the Schema of a sub-category, its Fields override the ones
+// of s with the same name.
+func (s *Schema) inherit(sub *Schema) *Schema {
+	if s == nil || sub == nil {
+		if sub != nil {
+			return sub
+		}
+		return s
+	}
+	n := Schema{Strict: s.Strict || sub.Strict, Fields: make(map[string]Field, len(s.Fields)+len(sub.Fields))}
+	for k, f := range s.Fields {
+		n.Fields[k] = f
+	}
+	for k, f := range sub.Fields {
+		n.Fields[k] = f
+	}
+	return &n
+}
+
+// validate checks the Meta of an element, returning the errors and the
+// missing keys with a default value. The inherited Meta is used only for the
+// Fields with Inherit and to skip the defaults. Loose allows number, bool and
+// date values as strings.
+func (s *Schema) validate(m, inherited Meta, loose bool) (errs []error, defaults Meta) {
+	for _, k := range s.keys() {
+		f := s.Fields[k]
+		v, ok := m[k]
+		if !ok || v == nil || v == "" {
+			_, isInherited := inherited[k]
+			if ok {
+				// null unsets the inherited value
+				isInherited = false
+			}
+			switch {
+			case f.Inherit && isInherited:
+			case f.Default != nil:
+				if isInherited {
+					continue
+				}
+				if defaults == nil {
+					defaults = make(Meta)
+				}
+				defaults[k] = f.Default
+			case f.Required:
+				errs = append(errs, fmt.Errorf("missing required field %q", k))
+			}
+			continue
+		}
+		if err := f.check(v, loose); err != nil {
+			errs = append(errs, fmt.Errorf("field %q: %s", k, err))
+		}
+	}
+	if s.Strict {
+		var unknown []string
+		for k := range m {
+			if _, ok := s.Fields[k]; !ok {
+				unknown = append(unknown, k)
+			}
+		}
+		sort.Strings(unknown)
+		for _, k := range unknown {
+			errs = append(errs, fmt.Errorf("unknown field %q", k))
+		}
+	}
+	return errs, defaults
+}
+
+// check verifies a value against the Field.
+func (f *Field) check(v interface{}, loose bool) error {
+	if !matchType(f.Type, v, loose) {
+		return fmt.Errorf("expected %s, got %T", f.Type, v)
+	}
+	s, isScalar := scalar(v)
+	if len(f.Enum) != 0 {
+		if !isScalar {
+			return fmt.Errorf("expected one of %v", f.Enum)
+		}
+		var found bool
+		for _, e := range f.Enum {
+			if fmt.Sprint(e) == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%q is not one of %v", s, f.Enum)
+		}
+	}
+	if f.pattern != nil && (!isScalar || !f.pattern.MatchString(s)) {
+		return fmt.Errorf("%q does not match %s", s, f.pattern)
+	}
+	return nil
+}
+
+func matchType(t string, v interface{}, loose bool) bool {
+	str, isString := v.(string)
+	switch t {
+	case "":
+		return true
+	case "string":
+		return isString
+	case "number":
+		switch v.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+		if _, err := strconv.ParseFloat(str, 64); isString && loose && err == nil {
+			return true
+		}
+	case "bool":
+		if _, ok := v.(bool); ok {
+			return true
+		}
+		if _, err := strconv.ParseBool(str); isString && loose && err == nil {
+			return true
+		}
+	case "date":
+		_, ok := Meta{"v": v}.Time("v")
+		return ok
+	case "list":
+		_, ok := v.([]interface{})
+		return ok
+	case "map":
+		return (Meta{"v": v}).Map("v") != nil
+	}
+	return false
+}
+
+// applySchema validates the Component Meta, setting the defaults that are
+// missing and not inherited from the Categories. It returns the defaults
+// that were set.
+func applySchema(s *Schema, cmp Component, inherited EffectiveMeta) ([]error, Meta) {
+	var loose bool
+	switch cmp.(type) {
+	case *Segment:
+	case *Checks, *Form:
+		loose = true
+	default:
+		return nil, nil
+	}
+	errs, defaults := s.validate(metaOf(cmp), inherited.Meta(), loose)
+	setDefaults(cmp, defaults)
+	return errs, defaults
+}
+
+// setDefaults sets the default values in the Component Meta.
+func setDefaults(cmp Component, defaults Meta) {
+	if len(defaults) == 0 {
+		return
+	}
+	switch v := cmp.(type) {
+	case *Segment:
+		if v.Meta == nil {
+			v.Meta = make(Meta, len(defaults))
+		}
+		for k, d := range defaults {
+			v.Meta[k] = d
+		}
+	case *Checks:
+		setStrings(&v.Meta, defaults)
+	case *Form:
+		setStrings(&v.Meta, defaults)
+	}
+}
+
+func setStrings(meta *map[string]string, defaults Meta) {
+	if *meta == nil {
+		*meta = make(map[string]string, len(defaults))
+	}
+	for k, d := range defaults {
+		v, _ := scalar(d)
+		(*meta)[k] = v
+	}
+}
+
+// schemaName returns the Item name of the Schema of a Category.
+func schemaName(prefix []string) string {
+	return path.Join(path.Join(prefix...), ".schema.yml")
+}
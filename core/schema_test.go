@@ -0,0 +1,149 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/go-tent/tent/item"
+	"github.com/go-tent/tent/source"
+)
+
+func TestSchema(t *testing.T) {
+	items := []item.Memory{
+		{ID: "s_free.md", Contents: []byte("---\nanything: 1\n---\n")},
+		{ID: "a/.schema.yml", Contents: []byte(`strict: true
+fields:
+  title: {type: string, required: true}
+  status: {enum: [draft, published], default: draft}
+  slug: {pattern: "^[a-z-]+$"}
+  count: {type: number}
+`)},
+		{ID: "a/s_one.md", Contents: []byte("---\ntitle: One\nslug: one\n---\nbody")},
+		{ID: "a/c_list.yml", Contents: []byte("title: List\ncount: 3\n")},
+		{ID: "a/b/.schema.yml", Contents: []byte("fields:\n  layout: {type: string}\n")},
+		{ID: "a/b/s_two.md", Contents: []byte("---\ntitel: Two\nslug: Two\nstatus: gone\nlayout: wide\n---\n")},
+	}
+	r, err := NewRoot(Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Validate(&source.Memory{Items: items})
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("Expected Errors, got %v", err)
+	}
+	exp := []string{
+		`a/b/s_two.md: Segment: field "slug": "Two" does not match ^[a-z-]+$`,
+		`a/b/s_two.md: Segment: field "status": "gone" is not one of [draft published]`,
+		`a/b/s_two.md: Segment: missing required field "title"`,
+		`a/b/s_two.md: Segment: unknown field "titel"`,
+	}
+	if len(errs) != len(exp) {
+		t.Fatalf("Expected %d errors, got %v", len(exp), errs)
+	}
+	for i := range exp {
+		if errs[i].Error() != exp[i] {
+			t.Fatalf("Expected %q, got %q", exp[i], errs[i])
+		}
+	}
+
+	items = items[:4]
+	if err := r.Decode(&source.Memory{Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	one, _ := r.Find("a/one")
+	if s := one.(*Segment).Meta.String("status"); s != "draft" {
+		t.Fatalf("Expected default status, got %q", s)
+	}
+	src, err := r.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for i, err := src.Next(); i != nil; i, err = src.Next() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range items {
+			if m.ID == i.Name() && string(m.Contents) != string(i.(item.Memory).Contents) {
+				t.Fatalf("%s: expected %q, got %q", m.ID, m.Contents, i.(item.Memory).Contents)
+			}
+		}
+		n++
+	}
+	if n != len(items) {
+		t.Fatalf("Expected %d items, got %d", len(items), n)
+	}
+}
+
+func TestSchemaInherited(t *testing.T) {
+	items := []item.Memory{
+		{ID: "a/.category.yml", Contents: []byte("title: A\nstatus: published\n")},
+		{ID: "a/.schema.yml", Contents: []byte(`fields:
+  title: {type: string, required: true, inherit: true}
+  status: {enum: [draft, published], default: draft}
+  layout: {default: wide}
+`)},
+		{ID: "a/s_one.md", Contents: []byte("---\nindex: 1\n---\nbody")},
+	}
+	r, err := NewRoot(Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Decode(&source.Memory{Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	one, _ := r.Find("a/one")
+	meta := one.(*Segment).Meta
+	if _, ok := meta["status"]; ok {
+		t.Fatalf("Expected inherited status, got %v", meta)
+	}
+	if l := meta.String("layout"); l != "wide" {
+		t.Fatalf("Expected %q, got %q", "wide", l)
+	}
+	e, _ := r.EffectiveMeta("a/one")
+	if s := e["status"].Value; s != "published" {
+		t.Fatalf("Expected %q, got %v", "published", s)
+	}
+}
+
+func TestSchemaOwnMeta(t *testing.T) {
+	items := []item.Memory{
+		{ID: "a/.category.yml", Contents: []byte("title: A\nicon: book\n")},
+		{ID: "a/.schema.yml", Contents: []byte(`strict: true
+fields:
+  title: {type: string, required: true}
+`)},
+		{ID: "a/s_one.md", Contents: []byte("---\nindex: 1\n---\nbody")},
+		{ID: "a/s_two.md", Contents: []byte("---\ntitle: Two\n---\nbody")},
+	}
+	r, err := NewRoot(Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, ok := r.Validate(&source.Memory{Items: items}).(Errors)
+	if !ok {
+		t.Fatalf("Expected Errors, got %v", errs)
+	}
+	exp := `a/s_one.md: Segment: missing required field "title"`
+	if len(errs) != 1 || errs[0].Error() != exp {
+		t.Fatalf("Expected %q, got %v", exp, errs)
+	}
+}
+
+func TestSchemaInvalid(t *testing.T) {
+	for _, s := range []string{
+		"fields:\n  a: {type: text}\n",
+		"fields:\n  a: {pattern: \"[\"}\n",
+		"fields:\n  a: {type: number, default: x}\n",
+		"fileds: {}\n",
+	} {
+		r, err := NewRoot(Components...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items := []item.Memory{{ID: ".schema.yml", Contents: []byte(s)}}
+		if err := r.Decode(&source.Memory{Items: items}); err == nil {
+			t.Fatalf("Expected error for %q", s)
+		}
+	}
+}
@@ -3,25 +3,36 @@ package core
 import (
 	"bufio"
 	"bytes"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-
-	"gopkg.in/yaml.v2"
 )
 
 // Segment is an Article.
 type Segment struct {
-	ID    string            `yaml:"-"`
-	Index float64           `yaml:"index,omitempty"`
-	Meta  map[string]string `yaml:",inline"`
-	Body  []byte            `yaml:"-"`
+	ID    string  `yaml:"-"`
+	Index float64 `yaml:"index,omitempty"`
+	Meta  Meta    `yaml:",inline"`
+	Body  []byte  `yaml:"-"`
+	// FrontMatter is the format of the header, YAML by default.
+	FrontMatter FrontMatter `yaml:"-" json:"-"`
+
+	// src is the decoded front matter, Encode patches YAML and keeps TOML and
+	// JSON if they did not change.
+	src []byte
+	// line is the number of lines before the Body.
+	line int
 }
 
 // GetID implements the Component interface.
 func (s *Segment) GetID() string { return s.ID }
 
+// SetID implements the Renamer interface.
+func (s *Segment) SetID(id string) { s.ID = id }
+
+// SetIndex implements the Indexer interface.
+func (s *Segment) SetIndex(i float64) { s.Index = i }
+
 // Order implements the Component interface.
 func (s *Segment) Order() float64 { return s.Index }
 
@@ -32,11 +43,9 @@ func (s Segment) String() string {
 // Encode returns Item contents.
 func (s *Segment) Encode() ([]byte, error) {
 	b := bytes.NewBuffer(nil)
-	fmt.Fprintln(b, "---")
-	if err := yaml.NewEncoder(b).Encode(s); err != nil {
+	if err := s.encodeMeta(b); err != nil {
 		return nil, err
 	}
-	fmt.Fprintln(b, "---")
 	if _, err := io.Copy(b, bytes.NewReader(s.Body)); err != nil {
 		return nil, err
 	}
@@ -51,41 +60,24 @@ func (s *Segment) Decode(id string, r io.Reader) (Component, error) {
 	return s.decode(id, r)
 }
 func (*Segment) decode(id string, r io.Reader) (*Segment, error) {
-	b := bufio.NewReader(r)
-	header, err := extractMeta(b)
+	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 	s := Segment{ID: id}
-	if err := yaml.NewDecoder(header).Decode(&s); err != nil {
+	r, err = s.decodeMeta(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
 		return nil, err
 	}
-	s.Body, err = ioutil.ReadAll(b)
+	body, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
+	s.Body = body
+	s.line = bytes.Count(data[:len(data)-len(body)], []byte("\n"))
 	return &s, nil
 }
 
-// extractMeta looks for "---\n" delimiters, returning what's between.
-func extractMeta(r *bufio.Reader) (io.Reader, error) {
-	row, _, err := r.ReadLine()
-	if err != nil {
-		return nil, err
-	}
-	if !bytes.Equal([]byte("---"), bytes.TrimSuffix(row, []byte("\r"))) {
-		return nil, errors.New("Invalid header")
-	}
-	b := bytes.NewBuffer(nil)
-	for {
-		row, _, err := r.ReadLine()
-		if err != nil {
-			return nil, err
-		}
-		if bytes.Equal([]byte("---"), bytes.TrimSuffix(row, []byte("\r"))) {
-			break
-		}
-		fmt.Fprintln(b, string(row))
-	}
-	return b, nil
-}
+// BodyLine returns the line of the Item where the Body starts, 1 if the
+// Segment was not decoded.
+func (s *Segment) BodyLine() int { return s.line + 1 }
@@ -6,7 +6,7 @@ import (
 )
 
 func TestSegment(t *testing.T) {
-	s1 := &Segment{ID: "a", Index: 10, Meta: map[string]string{"title": "segment"}, Body: []byte("# Title\n\ntext")}
+	s1 := &Segment{ID: "a", Index: 10, Meta: Meta{"title": "segment"}, Body: []byte("# Title\n\ntext")}
 	b, err := s1.Encode()
 	if err != nil {
 		t.Fatal(err)
@@ -31,4 +31,7 @@ func TestSegment(t *testing.T) {
 	if !bytes.Equal(s2.Body, s1.Body) {
 		t.Fatalf("Expected %v body, got %v", string(s1.Body), string(s2.Body))
 	}
+	if l := s2.BodyLine(); l != 5 {
+		t.Fatalf("Expected body at line 5, got %d", l)
+	}
 }
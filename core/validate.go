@@ -0,0 +1,321 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Form answer rules, see FieldError.
+const (
+	RuleRequired = "required"
+	RuleType     = "type"
+	RuleOption   = "option"
+	RuleMin      = "min"
+	RuleMax      = "max"
+	RulePattern  = "pattern"
+	RuleUnknown  = "unknown"
+	// RuleCondition is an invalid show_if or next_screen_if.
+	RuleCondition = "condition"
+)
+
+// FieldError is an invalid Form answer.
+type FieldError struct {
+	// Field is the FormItem name, empty for errors of the Form.
+	Field string `json:"field"`
+	// Rule is the failed check, one of the Rule constants.
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// FieldErrors is the result of Form.Validate.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	var s = make([]string, len(e))
+	for i := range e {
+		s[i] = e[i].Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Validate checks the answers to the Form, keyed by FormItem name. Values must
+// match the ItemType of the item and its Meta.
+// Items that are hidden or in skipped screens are ignored, answers to missing
+// items are errors. It returns nil if all are valid.
+func (f *Form) Validate(answers map[string]interface{}) FieldErrors {
+	var (
+		errs  FieldErrors
+		known = make(map[string]bool)
+	)
+	for _, s := range f.Screens {
+		for _, item := range s.Items {
+			known[item.Name] = true
+		}
+	}
+	path, err := f.Path(answers)
+	if err != nil {
+		return FieldErrors{{Rule: RuleCondition, Message: err.Error()}}
+	}
+	for _, i := range path {
+		items, err := f.Visible(i, answers)
+		if err != nil {
+			return FieldErrors{{Rule: RuleCondition, Message: err.Error()}}
+		}
+		for _, item := range items {
+			if err := item.validate(answers[item.Name]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	var unknown []string
+	for k := range answers {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		errs = append(errs, &FieldError{Field: k, Rule: RuleUnknown, Message: "unknown field"})
+	}
+	return errs
+}
+
+// validate checks the answer to the item, nil if valid.
+func (item *FormItem) validate(v interface{}) *FieldError {
+	if isEmpty(v) {
+		if item.Required {
+			return &FieldError{Field: item.Name, Rule: RuleRequired, Message: "is required"}
+		}
+		return nil
+	}
+	t, ok := LookupItemType(item.Type)
+	if !ok || t.Validate == nil {
+		return nil
+	}
+	if err := t.Validate(item, v); err != nil {
+		err.Field = item.Name
+		return err
+	}
+	return nil
+}
+
+func invalid(rule, format string, args ...interface{}) *FieldError {
+	return &FieldError{Rule: rule, Message: fmt.Sprintf(format, args...)}
+}
+
+func checkText(item *FormItem, v interface{}) *FieldError {
+	s, ok := v.(string)
+	if !ok {
+		return invalid(RuleType, "expected text, got %T", v)
+	}
+	return checkString(item, s)
+}
+
+func checkEmail(item *FormItem, v interface{}) *FieldError {
+	s, ok := v.(string)
+	if !ok {
+		return invalid(RuleType, "expected email, got %T", v)
+	}
+	if a, err := mail.ParseAddress(s); err != nil || a.Address != s {
+		return invalid(RuleType, "%q is not an email address", s)
+	}
+	return checkString(item, s)
+}
+
+// checkString verifies the length and the pattern of a text.
+func checkString(item *FormItem, s string) *FieldError {
+	n := float64(utf8.RuneCountInString(s))
+	if min, ok := metaNumber(item.Meta, "min"); ok && n < min {
+		return invalid(RuleMin, "must be at least %v characters", min)
+	}
+	if max, ok := metaNumber(item.Meta, "max"); ok && n > max {
+		return invalid(RuleMax, "must be at most %v characters", max)
+	}
+	p, ok := Meta(item.Meta)["pattern"].(string)
+	if !ok {
+		return nil
+	}
+	re, err := regexp.Compile("^(?:" + p + ")$")
+	if err != nil {
+		return invalid(RulePattern, "invalid pattern %q", p)
+	}
+	if !re.MatchString(s) {
+		return invalid(RulePattern, "must match %s", p)
+	}
+	return nil
+}
+
+func checkNumber(item *FormItem, v interface{}) *FieldError {
+	n, ok := number(v)
+	if !ok {
+		return invalid(RuleType, "expected number, got %v", v)
+	}
+	if min, ok := metaNumber(item.Meta, "min"); ok && n < min {
+		return invalid(RuleMin, "must be at least %v", min)
+	}
+	if max, ok := metaNumber(item.Meta, "max"); ok && n > max {
+		return invalid(RuleMax, "must be at most %v", max)
+	}
+	return nil
+}
+
+func checkDate(item *FormItem, v interface{}) *FieldError {
+	t, ok := Meta{"v": v}.Time("v")
+	if !ok {
+		return invalid(RuleType, "expected date, got %v", v)
+	}
+	if min, ok := Meta(item.Meta).Time("min"); ok && t.Before(min) {
+		return invalid(RuleMin, "must be on or after %s", min.Format(dateFormat))
+	}
+	if max, ok := Meta(item.Meta).Time("max"); ok && t.After(max) {
+		return invalid(RuleMax, "must be on or before %s", max.Format(dateFormat))
+	}
+	return nil
+}
+
+const dateFormat = "2006-01-02"
+
+func checkSelect(item *FormItem, v interface{}) *FieldError {
+	s, ok := scalar(v)
+	if !ok {
+		return invalid(RuleType, "expected a single value, got %v", v)
+	}
+	return checkOption(item, s)
+}
+
+// checkCheckbox verifies a boolean, or a list of options if the item has
+// any, with "min" and "max" the number of selected options.
+func checkCheckbox(item *FormItem, v interface{}) *FieldError {
+	if len(Meta(item.Meta).Strings("option")) == 0 {
+		b, ok := boolean(v)
+		switch {
+		case !ok:
+			return invalid(RuleType, "expected true or false, got %v", v)
+		case !b && item.Required:
+			return invalid(RuleRequired, "is required")
+		}
+		return nil
+	}
+	list, ok := answerList(v)
+	if !ok {
+		return invalid(RuleType, "expected a list of options, got %v", v)
+	}
+	for _, s := range list {
+		if err := checkOption(item, s); err != nil {
+			return err
+		}
+	}
+	n := float64(len(list))
+	if min, ok := metaNumber(item.Meta, "min"); ok && n < min {
+		return invalid(RuleMin, "must select at least %v options", min)
+	}
+	if max, ok := metaNumber(item.Meta, "max"); ok && n > max {
+		return invalid(RuleMax, "must select at most %v options", max)
+	}
+	return nil
+}
+
+// checkOption verifies that s is in the "option" Meta, if present.
+func checkOption(item *FormItem, s string) *FieldError {
+	options := Meta(item.Meta).Strings("option")
+	if len(options) == 0 {
+		return nil
+	}
+	for _, o := range options {
+		if o == s {
+			return nil
+		}
+	}
+	return invalid(RuleOption, "%q is not one of %v", s, options)
+}
+
+// isEmpty tells if an answer is missing.
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	}
+	return false
+}
+
+// answerList returns the scalars of a list answer, a scalar is a list of one.
+func answerList(v interface{}) ([]string, bool) {
+	switch v := v.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := scalar(e)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, s)
+		}
+		return list, true
+	}
+	s, ok := scalar(v)
+	return []string{s}, ok
+}
+
+// number parses a numeric answer, strings included.
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// boolean parses a boolean answer, "on" is the value of HTML checkboxes.
+func boolean(v interface{}) (bool, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case string:
+		if v == "on" {
+			return true, true
+		}
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	}
+	return false, false
+}
+
+func metaNumber(m Map, key string) (float64, bool) {
+	v, ok := m[key]
+	if !ok {
+		return 0, false
+	}
+	return number(v)
+}
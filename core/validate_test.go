@@ -0,0 +1,75 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormValidate(t *testing.T) {
+	f := Form{Screens: []FormScreen{
+		{Items: []FormItem{
+			{Name: "name", Type: "text", Required: true, Meta: Map{"min": 2, "max": 5, "pattern": "[a-z]+"}},
+			{Name: "email", Type: "email"},
+			{Name: "age", Type: "number", Meta: Map{"min": 18, "max": 99}},
+		}},
+		{Items: []FormItem{
+			{Name: "day", Type: "date", Meta: Map{"min": "2019-01-01"}},
+			{Name: "color", Type: "select", Meta: Map{"option": []interface{}{"red", "blue"}}},
+			{Name: "tags", Type: "checkbox", Meta: Map{"option": []interface{}{"a", "b", "c"}, "max": 2}},
+			{Name: "agree", Type: "checkbox", Required: true},
+			{Name: "note", Type: "custom"},
+		}},
+	}}
+	valid := map[string]interface{}{
+		"name":  "bob",
+		"email": "bob@example.com",
+		"age":   "42",
+		"day":   "2019-05-01",
+		"color": "red",
+		"tags":  []interface{}{"a", "c"},
+		"agree": "on",
+		"note":  42,
+	}
+	if errs := f.Validate(valid); errs != nil {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	for _, tc := range []struct {
+		field string
+		value interface{}
+		rule  string
+	}{
+		{"name", "", RuleRequired},
+		{"name", 7, RuleType},
+		{"name", "b", RuleMin},
+		{"name", "robert", RuleMax},
+		{"name", "Bob", RulePattern},
+		{"email", "Bob <bob@example.com>", RuleType},
+		{"age", "old", RuleType},
+		{"age", 17, RuleMin},
+		{"age", 100.5, RuleMax},
+		{"day", "yesterday", RuleType},
+		{"day", "2018-12-31", RuleMin},
+		{"color", "green", RuleOption},
+		{"color", []interface{}{"red"}, RuleType},
+		{"tags", []string{"a", "d"}, RuleOption},
+		{"tags", []string{"a", "b", "c"}, RuleMax},
+		{"agree", false, RuleRequired},
+		{"agree", "false", RuleRequired},
+		{"agree", "maybe", RuleType},
+		{"other", "x", RuleUnknown},
+	} {
+		answers := make(map[string]interface{}, len(valid)+1)
+		for k, v := range valid {
+			answers[k] = v
+		}
+		answers[tc.field] = tc.value
+		exp := FieldErrors{{Field: tc.field, Rule: tc.rule}}
+		errs := f.Validate(answers)
+		for _, e := range errs {
+			e.Message = ""
+		}
+		if !reflect.DeepEqual(errs, exp) {
+			t.Fatalf("%s=%v: expected %v, got %v", tc.field, tc.value, exp, errs)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package core
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// encodeYAML marshals v. If src, the YAML v was decoded from, is not empty it
+// is patched instead: values that did not change keep their original text,
+// comments and position, so that edits produce minimal diffs.
+func encodeYAML(src []byte, v interface{}) ([]byte, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(src)) == 0 {
+		return b, nil
+	}
+	if out, ok := patchYAML(src, b); ok {
+		return out, nil
+	}
+	return b, nil
+}
+
+// patchYAML applies the differences between src and the YAML b to src, it
+// fails if src is not a block mapping.
+func patchYAML(src, b []byte) ([]byte, bool) {
+	var old, new yaml3.Node
+	if yaml3.Unmarshal(src, &old) != nil || yaml3.Unmarshal(b, &new) != nil {
+		return nil, false
+	}
+	om, nm := docMapping(&old), docMapping(&new)
+	if om == nil || nm == nil || !isBlock(om) || hasAlias(om) {
+		return nil, false
+	}
+	p := patcher{old: splitLines(string(src)), new: splitLines(string(b))}
+	if !p.mapping(om, nm, 0, len(p.old), 0, len(p.new)) {
+		return nil, false
+	}
+	if len(p.edits) == 0 {
+		return src, true
+	}
+	sort.Slice(p.edits, func(i, j int) bool {
+		if p.edits[i].start != p.edits[j].start {
+			return p.edits[i].start > p.edits[j].start
+		}
+		return p.edits[i].end > p.edits[j].end
+	})
+	lines := p.old
+	for _, e := range p.edits {
+		lines = append(lines[:e.start:e.start], append(e.text, lines[e.end:]...)...)
+	}
+	for i, l := range lines {
+		if !strings.HasSuffix(l, "\n") {
+			lines[i] = l + "\n"
+		}
+	}
+	return []byte(strings.Join(lines, "")), true
+}
+
+// docMapping returns the mapping at the root of a document.
+func docMapping(n *yaml3.Node) *yaml3.Node {
+	if n.Kind != yaml3.DocumentNode || len(n.Content) != 1 || n.Content[0].Kind != yaml3.MappingNode {
+		return nil
+	}
+	return n.Content[0]
+}
+
+func isBlock(n *yaml3.Node) bool {
+	return (n.Kind == yaml3.MappingNode || n.Kind == yaml3.SequenceNode) &&
+		n.Style&yaml3.FlowStyle == 0 && len(n.Content) != 0
+}
+
+func hasAlias(n *yaml3.Node) bool {
+	if n.Kind == yaml3.AliasNode || n.Anchor != "" {
+		return true
+	}
+	for _, c := range n.Content {
+		if hasAlias(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue tells if two nodes decode to the same value.
+func sameValue(a, b *yaml3.Node) bool {
+	var va, vb interface{}
+	if a.Decode(&va) != nil || b.Decode(&vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// splitLines splits s keeping the line endings.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edit replaces the lines from start to end with text.
+type edit struct {
+	start, end int
+	text       []string
+}
+
+// span is the lines of an entry of a collection, head includes the comments
+// on top of it.
+type span struct {
+	head, start, end int
+	// col is the column of the entry, the text before it is its prefix.
+	col int
+}
+
+type patcher struct {
+	old, new []string
+	edits    []edit
+}
+
+// spans returns the line spans of the entries of a block collection, between
+// the lines begin and end. The comments and blank lines after an entry are
+// excluded if they are not indented more than it.
+func spans(lines []string, nodes []*yaml3.Node, begin, end int) []span {
+	s := make([]span, len(nodes))
+	for i, n := range nodes {
+		s[i].start, s[i].col = n.Line-1, n.Column-1
+	}
+	for i := range s {
+		limit := end
+		if i < len(s)-1 {
+			limit = s[i+1].start
+		}
+		s[i].end = limit
+		for s[i].end > s[i].start+1 && isFiller(lines[s[i].end-1], s[i].col) {
+			s[i].end--
+		}
+		low := begin
+		if i > 0 {
+			low = s[i-1].end
+		}
+		s[i].head = s[i].start
+		for s[i].head > low && isComment(lines[s[i].head-1]) {
+			s[i].head--
+		}
+	}
+	return s
+}
+
+func isComment(l string) bool { return strings.HasPrefix(strings.TrimSpace(l), "#") }
+
+func isFiller(l string, indent int) bool {
+	t := strings.TrimLeft(l, " ")
+	if strings.TrimSpace(t) == "" {
+		return true
+	}
+	return strings.HasPrefix(t, "#") && len(l)-len(t) <= indent
+}
+
+// text returns the lines of the new span s, moved to the column col with the
+// given prefix for the first line.
+func (p *patcher) text(s span, prefix string, col int) []string {
+	text := make([]string, s.end-s.start)
+	for i, l := range p.new[s.start:s.end] {
+		if i == 0 {
+			text[i] = prefix + l[s.col:]
+			continue
+		}
+		t := strings.TrimLeft(l, " ")
+		if n := len(l) - len(t); n > s.col {
+			t = strings.Repeat(" ", n-s.col) + t
+		}
+		text[i] = strings.Repeat(" ", col) + t
+		if strings.TrimSpace(t) == "" {
+			text[i] = t
+		}
+	}
+	return text
+}
+
+// prefix returns the text before the entry in its first line.
+func (p *patcher) prefix(s span) string { return p.old[s.start][:s.col] }
+
+// mapping patches the old mapping o with the new one n, it fails if the
+// change cannot be applied to the entries.
+func (p *patcher) mapping(o, n *yaml3.Node, obegin, oend, nbegin, nend int) bool {
+	var okeys, nkeys []*yaml3.Node
+	for i := 0; i < len(o.Content); i += 2 {
+		okeys = append(okeys, o.Content[i])
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		nkeys = append(nkeys, n.Content[i])
+	}
+	var (
+		ospans, nspans = spans(p.old, okeys, obegin, oend), spans(p.new, nkeys, nbegin, nend)
+		done           = len(p.edits)
+		found          = make(map[string]bool)
+	)
+	for i, k := range okeys {
+		j := -1
+		for x := range nkeys {
+			if nkeys[x].Value == k.Value {
+				j = x
+				break
+			}
+		}
+		if j == -1 {
+			if strings.TrimSpace(p.prefix(ospans[i])) != "" {
+				p.edits = p.edits[:done]
+				return false
+			}
+			// the comments on top of the document are kept
+			if i == 0 && obegin == 0 {
+				ospans[i].head = ospans[i].start
+			}
+			p.edits = append(p.edits, edit{start: ospans[i].head, end: ospans[i].end})
+			continue
+		}
+		found[k.Value] = true
+		ov, nv := o.Content[i*2+1], n.Content[j*2+1]
+		if sameValue(ov, nv) {
+			continue
+		}
+		if ov.Line > k.Line && isBlock(ov) && isBlock(nv) && ov.Kind == nv.Kind &&
+			p.collection(ov, nv, ospans[i].start+1, ospans[i].end, nspans[j].start+1, nspans[j].end) {
+			continue
+		}
+		p.replace(ospans[i], nspans[j])
+	}
+	var insert []string
+	for j, k := range nkeys {
+		if !found[k.Value] {
+			insert = append(insert, p.text(nspans[j], strings.Repeat(" ", ospans[0].col), ospans[0].col)...)
+		}
+	}
+	if len(insert) != 0 {
+		last := ospans[len(ospans)-1].end
+		p.edits = append(p.edits, edit{start: last, end: last, text: insert})
+	}
+	return true
+}
+
+// sequence patches the old sequence o with the new one n, the items that did
+// not change at the start and the end are kept, the others are paired by index.
+func (p *patcher) sequence(o, n *yaml3.Node, obegin, oend, nbegin, nend int) bool {
+	var (
+		ospans, nspans = spans(p.old, o.Content, obegin, oend), spans(p.new, n.Content, nbegin, nend)
+		prefix         = p.prefix(ospans[0])
+		lo, ln         = len(o.Content), len(n.Content)
+		pre, suf       int
+	)
+	if strings.TrimSpace(prefix) != "-" {
+		return false
+	}
+	for pre < lo && pre < ln && sameValue(o.Content[pre], n.Content[pre]) {
+		pre++
+	}
+	for suf < lo-pre && suf < ln-pre && sameValue(o.Content[lo-1-suf], n.Content[ln-1-suf]) {
+		suf++
+	}
+	i, j := pre, pre
+	for ; i < lo-suf && j < ln-suf; i, j = i+1, j+1 {
+		ov, nv := o.Content[i], n.Content[j]
+		if ov.Kind == yaml3.MappingNode && isBlock(ov) && isBlock(nv) && nv.Kind == yaml3.MappingNode &&
+			p.mapping(ov, nv, ospans[i].start, ospans[i].end, nspans[j].start, nspans[j].end) {
+			continue
+		}
+		p.replace(ospans[i], nspans[j])
+	}
+	for ; i < lo-suf; i++ {
+		p.edits = append(p.edits, edit{start: ospans[i].head, end: ospans[i].end})
+	}
+	var insert []string
+	for ; j < ln-suf; j++ {
+		insert = append(insert, p.text(nspans[j], prefix, ospans[0].col)...)
+	}
+	if len(insert) != 0 {
+		at := ospans[0].head
+		if i > 0 {
+			at = ospans[i-1].end
+		}
+		p.edits = append(p.edits, edit{start: at, end: at, text: insert})
+	}
+	return true
+}
+
+func (p *patcher) collection(o, n *yaml3.Node, obegin, oend, nbegin, nend int) bool {
+	if o.Kind == yaml3.MappingNode {
+		return p.mapping(o, n, obegin, oend, nbegin, nend)
+	}
+	return p.sequence(o, n, obegin, oend, nbegin, nend)
+}
+
+// replace swaps the old span with the new one.
+func (p *patcher) replace(o, n span) {
+	p.edits = append(p.edits, edit{start: o.start, end: o.end, text: p.text(n, p.prefix(o), o.col)})
+}
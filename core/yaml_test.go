@@ -0,0 +1,159 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodePatch(t *testing.T) {
+	const src = `# checklist
+title: Fruits   # shown on top
+index: 10
+
+list:
+  # first group
+  - label: fruits
+    children:
+      - check: apple
+      - check: pear # ripe
+  - label: citrus
+    children:
+      - check: lemon
+`
+	for _, tc := range []struct {
+		name string
+		edit func(c *Checks)
+		exp  string
+	}{
+		{"unchanged", func(*Checks) {}, src},
+		{"scalar", func(c *Checks) { c.Index = 20 }, `# checklist
+title: Fruits   # shown on top
+index: 20
+
+list:
+  # first group
+  - label: fruits
+    children:
+      - check: apple
+      - check: pear # ripe
+  - label: citrus
+    children:
+      - check: lemon
+`},
+		{"nested", func(c *Checks) { c.List[1].Children[0].Check = "lime" }, `# checklist
+title: Fruits   # shown on top
+index: 10
+
+list:
+  # first group
+  - label: fruits
+    children:
+      - check: apple
+      - check: pear # ripe
+  - label: citrus
+    children:
+      - check: lime
+`},
+		{"append", func(c *Checks) {
+			c.List[0].Children = append(c.List[0].Children, Check{Check: "melon", Label: "Melon"})
+		}, `# checklist
+title: Fruits   # shown on top
+index: 10
+
+list:
+  # first group
+  - label: fruits
+    children:
+      - check: apple
+      - check: pear # ripe
+      - check: melon
+        label: Melon
+  - label: citrus
+    children:
+      - check: lemon
+`},
+		{"remove", func(c *Checks) { c.List = c.List[1:]; delete(c.Meta, "title") }, `# checklist
+index: 10
+
+list:
+  - label: citrus
+    children:
+      - check: lemon
+`},
+		{"insert", func(c *Checks) {
+			c.List = append([]Check{{Check: "nuts"}}, c.List...)
+		}, `# checklist
+title: Fruits   # shown on top
+index: 10
+
+list:
+  - check: nuts
+  # first group
+  - label: fruits
+    children:
+      - check: apple
+      - check: pear # ripe
+  - label: citrus
+    children:
+      - check: lemon
+`},
+		{"add", func(c *Checks) { c.Meta["author"] = "me" }, `# checklist
+title: Fruits   # shown on top
+index: 10
+
+list:
+  # first group
+  - label: fruits
+    children:
+      - check: apple
+      - check: pear # ripe
+  - label: citrus
+    children:
+      - check: lemon
+author: me
+`},
+	} {
+		c, err := (*Checks).decode(nil, "a", bytes.NewReader([]byte(src)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tc.edit(c)
+		b, err := c.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.exp {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.exp, b)
+		}
+	}
+}
+
+func TestEncodePatchFallback(t *testing.T) {
+	c, err := (*Checks).decode(nil, "a", bytes.NewReader([]byte("{index: 1, list: [{check: a}]}")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Index = 2
+	b, err := c.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "index: 2\nlist:\n- check: a\n"; string(b) != exp {
+		t.Fatalf("Expected %q, got %q", exp, b)
+	}
+}
+
+func TestEncodePatchSegment(t *testing.T) {
+	s, err := (*Segment).decode(nil, "a", bytes.NewReader([]byte("---\n# draft\ntitle: \"One\"\nindex: 1\n---\nbody")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Index = 3
+	b, err := s.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "---\n# draft\ntitle: \"One\"\nindex: 3\n---\nbody"; string(b) != exp {
+		t.Fatalf("Expected %q, got %q", exp, b)
+	}
+}
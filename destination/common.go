@@ -5,6 +5,7 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"os"
 
 	"github.com/go-tent/tent/item"
@@ -25,8 +26,12 @@ type Memory struct {
 
 // Hash returns the SHA1 for the item.
 func (m *Memory) Hash(ctx context.Context, i item.Item) (string, error) {
+	b, ok := m.items[i.Name()]
+	if !ok {
+		return "", os.ErrNotExist
+	}
 	h := sha1.New()
-	if _, err := h.Write(m.items[i.Name()]); err != nil {
+	if _, err := h.Write(b); err != nil {
 		return "", err
 	}
 	return hex.EncodeToString(h.Sum(nil)), nil
@@ -34,26 +39,21 @@ func (m *Memory) Hash(ctx context.Context, i item.Item) (string, error) {
 
 // Create adds a new Item.
 func (m *Memory) Create(ctx context.Context, i item.Item) error {
-	_, ok := m.items[i.Name()]
-	if ok {
+	if _, ok := m.items[i.Name()]; ok {
 		return os.ErrExist
 	}
-	i.Content()
-	return nil
+	return m.write(i)
 }
 
 // Update writes an existing Item.
 func (m *Memory) Update(ctx context.Context, i item.Item, hash string) error {
-	name := i.Name()
-	b, ok := m.items[name]
-	if !ok {
+	if _, ok := m.items[i.Name()]; !ok {
 		return os.ErrNotExist
 	}
 	if err := m.ensureHash(ctx, i, hash); err != nil {
 		return err
 	}
-	m.items[name] = b
-	return nil
+	return m.write(i)
 }
 
 // Delete removes an existing Item.
@@ -79,3 +79,20 @@ func (m *Memory) ensureHash(ctx context.Context, i item.Item, hash string) error
 	}
 	return nil
 }
+
+func (m *Memory) write(i item.Item) error {
+	r, err := i.Content()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if m.items == nil {
+		m.items = make(map[string][]byte)
+	}
+	m.items[i.Name()] = b
+	return nil
+}
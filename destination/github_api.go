@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"os"
 	"path"
 	"strings"
 
@@ -34,11 +36,14 @@ type GithubAPI struct {
 	config RepoCfg
 }
 
-// Hash returns file hash using Github API.
+// Hash returns file hash using Github API, os.ErrNotExist if missing.
 func (g *GithubAPI) Hash(ctx context.Context, i item.Item) (string, error) {
 	var o = github.RepositoryContentGetOptions{Ref: g.config.Branch}
-	contents, _, _, err := g.client.GetContents(ctx, g.config.Owner, g.config.Repo, i.Name(), &o)
+	contents, _, resp, err := g.client.GetContents(ctx, g.config.Owner, g.config.Repo, i.Name(), &o)
 	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", os.ErrNotExist
+		}
 		return "", err
 	}
 	return contents.GetSHA(), nil
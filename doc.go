@@ -1,4 +1,4 @@
-// Package tent is main command for the Tent project.
+// Package tent is the root of the Tent project, the main command is in cmd/tent.
 //
 // Source code and other details for the project are available at GitHub:
 //
@@ -1,8 +1,30 @@
 module github.com/go-tent/tent
 
-go 1.12
+go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.2.1
+	github.com/google/go-github v17.0.0+incompatible
+	github.com/yuin/goldmark v1.4.12
+	golang.org/x/oauth2 v0.20.0
 	gopkg.in/src-d/go-git.v4 v4.10.0
 	gopkg.in/yaml.v2 v2.2.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/emirpasic/gods v1.9.0 // indirect
+	github.com/google/go-querystring v1.0.0 // indirect
+	github.com/jbenet/go-context v0.0.0-20150711004518-d14ea06fba99 // indirect
+	github.com/kevinburke/ssh_config v0.0.0-20180830205328-81db2a75821e // indirect
+	github.com/mitchellh/go-homedir v1.0.0 // indirect
+	github.com/pelletier/go-buffruneio v0.2.0 // indirect
+	github.com/sergi/go-diff v1.0.0 // indirect
+	github.com/src-d/gcfg v1.4.0 // indirect
+	github.com/xanzy/ssh-agent v0.2.0 // indirect
+	golang.org/x/crypto v0.0.0-20180904163835-0709b304e793 // indirect
+	golang.org/x/net v0.0.0-20180906233101-161cd47e91fd // indirect
+	golang.org/x/sys v0.0.0-20180903190138-2b024373dcd9 // indirect
+	gopkg.in/src-d/go-billy.v4 v4.2.1 // indirect
+	gopkg.in/warnings.v0 v0.1.2 // indirect
 )
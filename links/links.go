@@ -0,0 +1,173 @@
+// Package links checks the references between the elements of a content tree.
+//
+// Links and images in Segment bodies are resolved like the render package
+// does: broken references and elements that nothing references are reported.
+package links
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-tent/tent/core"
+	"github.com/go-tent/tent/render"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// Link is a reference in a Segment body.
+type Link struct {
+	// Path is the Item name of the Segment, Line is the line in the Item.
+	Path string
+	Line int
+	// Dest is the destination as written.
+	Dest  string
+	Image bool
+}
+
+func (l Link) String() string {
+	kind := "link"
+	if l.Image {
+		kind = "image"
+	}
+	return fmt.Sprintf("%s:%d: broken %s %q", l.Path, l.Line, kind, l.Dest)
+}
+
+// Report is the result of Check.
+type Report struct {
+	// Broken are the references to missing elements.
+	Broken []Link
+	// Orphans are the Item names of the Components that no Segment references.
+	Orphans []string
+}
+
+// Check analyzes the tree under c.
+func Check(c *core.Category) Report {
+	var (
+		r     Report
+		items = make(map[string]string) // Item name -> tree path
+		used  = make(map[string]bool)   // tree path
+		refs  []ref
+	)
+	c.Walk(func(p string, _ []*core.Category, cmp core.Component) error {
+		dir := path.Dir(p)
+		if dir == "." {
+			dir = ""
+		}
+		if _, ok := cmp.(*core.Category); ok {
+			return nil
+		}
+		var prefix []string
+		if dir != "" {
+			prefix = strings.Split(dir, "/")
+		}
+		name := core.ItemName(prefix, cmp)
+		items[name] = p
+		if s, ok := cmp.(*core.Segment); ok {
+			for _, l := range Parse(s) {
+				l.Path = name
+				refs = append(refs, ref{dir: dir, link: l})
+			}
+		}
+		return nil
+	})
+	for _, f := range refs {
+		t, ok := render.Resolve(f.dir, f.link.Dest)
+		if !ok {
+			continue
+		}
+		if p, ok := resolve(c, items, t); ok {
+			used[p] = true
+			continue
+		}
+		r.Broken = append(r.Broken, f.link)
+	}
+	for name, p := range items {
+		if !used[p] {
+			r.Orphans = append(r.Orphans, name)
+		}
+	}
+	sort.Strings(r.Orphans)
+	return r
+}
+
+// ref is a Link with the path of the Category of its Segment.
+type ref struct {
+	dir  string
+	link Link
+}
+
+// resolve returns the tree path of the Target, if it exists.
+func resolve(c *core.Category, items map[string]string, t render.Target) (string, bool) {
+	if t.Segment {
+		cmp, ok := c.Find(t.Path)
+		if _, isSegment := cmp.(*core.Segment); ok && isSegment {
+			return t.Path, true
+		}
+		return "", false
+	}
+	if p, ok := items[t.Path]; ok {
+		return p, true
+	}
+	if cmp, ok := c.Find(t.Path); ok {
+		if _, isCategory := cmp.(*core.Category); isCategory {
+			return t.Path, true
+		}
+	}
+	return "", false
+}
+
+// md parses the Segment bodies with the extensions used for rendering.
+var md = goldmark.New(goldmark.WithExtensions(extension.Table, extension.Footnote))
+
+// Parse returns the links and images in the Segment body, with the Item lines.
+func Parse(s *core.Segment) []Link {
+	var (
+		doc   = md.Parser().Parse(text.NewReader(s.Body))
+		links []Link
+	)
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var l Link
+		switch n := n.(type) {
+		case *ast.Link:
+			l.Dest = string(n.Destination)
+		case *ast.Image:
+			l.Dest, l.Image = string(n.Destination), true
+		default:
+			return ast.WalkContinue, nil
+		}
+		l.Line = s.BodyLine() + bytes.Count(s.Body[:offset(n)], []byte("\n"))
+		links = append(links, l)
+		return ast.WalkContinue, nil
+	})
+	return links
+}
+
+// offset returns the position of an inline node in the source, using its
+// text or the one of its block.
+func offset(n ast.Node) int {
+	var pos = -1
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := c.(*ast.Text); ok && entering {
+			pos = t.Segment.Start
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	if pos != -1 {
+		return pos
+	}
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		if p.Type() == ast.TypeBlock && p.Lines().Len() != 0 {
+			return p.Lines().At(0).Start
+		}
+	}
+	return 0
+}
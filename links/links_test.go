@@ -0,0 +1,54 @@
+package links
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-tent/tent/core"
+	"github.com/go-tent/tent/item"
+	"github.com/go-tent/tent/source"
+)
+
+func TestCheck(t *testing.T) {
+	items := []item.Memory{
+		{ID: "guides/s_intro.md", Contents: []byte(`---
+title: Intro
+---
+# Intro
+
+See [setup](s_setup.md#linux) and [the list](../c_todo.yml).
+Also [missing](s_gone.md), [site](https://example.com)
+and [all guides](../guides/).
+
+![diagram](img/flow.png)
+![old](img/old.png)
+`)},
+		{ID: "guides/s_setup.md", Contents: []byte("---\ntitle: Setup\n---\n[back](s_intro.md)\n")},
+		{ID: "guides/img/flow.png", Contents: []byte("png")},
+		{ID: "guides/img/unused.png", Contents: []byte("png")},
+		{ID: "c_todo.yml", Contents: []byte("list:\n- check: a\n")},
+		{ID: "s_lonely.md", Contents: []byte("---\ntitle: Lonely\n---\n")},
+	}
+	r, err := core.NewRoot(core.Components...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Decode(&source.Memory{Items: items}); err != nil {
+		t.Fatal(err)
+	}
+	report := Check(r.Category)
+	broken := []Link{
+		{Path: "guides/s_intro.md", Line: 7, Dest: "s_gone.md"},
+		{Path: "guides/s_intro.md", Line: 11, Dest: "img/old.png", Image: true},
+	}
+	if !reflect.DeepEqual(report.Broken, broken) {
+		t.Fatalf("Expected %v, got %v", broken, report.Broken)
+	}
+	orphans := []string{"guides/img/unused.png", "s_lonely.md"}
+	if !reflect.DeepEqual(report.Orphans, orphans) {
+		t.Fatalf("Expected %v, got %v", orphans, report.Orphans)
+	}
+	if s := broken[1].String(); s != `guides/s_intro.md:11: broken image "img/old.png"` {
+		t.Fatalf("Unexpected %q", s)
+	}
+}
@@ -0,0 +1,308 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+
+	"github.com/go-tent/tent/core"
+)
+
+// FormRenderer writes Forms as HTML, with a fieldset for each screen. The
+// templates are:
+//
+//	form       executed with FormData
+//	screen     executed with ScreenData
+//	item:TYPE  executed with ItemData, for each FormItem type
+//	item       executed with ItemData for the types without a template
+type FormRenderer struct {
+	t *template.Template
+}
+
+// NewFormRenderer returns a FormRenderer with the default templates.
+func NewFormRenderer() *FormRenderer {
+	return &FormRenderer{t: template.Must(template.New("").Parse(formTemplates))}
+}
+
+// Template defines or overrides the template with the given name, it must be
+// called before any rendering.
+func (r *FormRenderer) Template(name, text string) error {
+	_, err := r.t.New(name).Parse(text)
+	return err
+}
+
+// FormData is the data of the form template.
+type FormData struct {
+	Form    *core.Form
+	Screens []template.HTML
+	// Errors are the messages of the errors without a field.
+	Errors []string
+}
+
+// ScreenData is the data of the screen template.
+type ScreenData struct {
+	core.FormScreen
+	Index int
+	Title string
+	Items []template.HTML
+}
+
+// ItemData is the data of the item templates.
+type ItemData struct {
+	core.FormItem
+	// ID is the id of the input element, other elements use it as prefix.
+	ID          string
+	Label       string
+	Description string
+	// InputType is the type of the input element of scalar items.
+	InputType string
+	// Mandatory is false for the Required items that conditions can hide.
+	Mandatory bool
+	// Value is the answer as string, Selected contains the selected options.
+	Value    string
+	Selected map[string]bool
+	Options  []string
+	Error    string
+	// DescribedBy are the ids of the description and the error, if any.
+	DescribedBy string
+}
+
+// Render writes the HTML of the Form, with the answers and the errors, that
+// can be nil.
+func (r *FormRenderer) Render(w io.Writer, f *core.Form, answers map[string]interface{}, errs core.FieldErrors) error {
+	var (
+		data      = FormData{Form: f}
+		messages  = make(map[string]string)
+		mandatory = make(map[string]bool)
+	)
+	for _, e := range errs {
+		if e.Field == "" {
+			data.Errors = append(data.Errors, e.Message)
+		} else if _, ok := messages[e.Field]; !ok {
+			messages[e.Field] = e.Message
+		}
+	}
+	for _, name := range f.JSONSchema().Required {
+		mandatory[name] = true
+	}
+	for i, s := range f.Screens {
+		screen := ScreenData{FormScreen: s, Index: i, Title: core.Meta(s.Meta).String("title")}
+		for _, item := range s.Items {
+			d := newItemData(item, answers[item.Name], messages[item.Name])
+			d.Mandatory = mandatory[item.Name]
+			name := "item:" + item.Type
+			if r.t.Lookup(name) == nil {
+				name = "item"
+			}
+			html, err := r.execute(name, d)
+			if err != nil {
+				return err
+			}
+			screen.Items = append(screen.Items, html)
+		}
+		html, err := r.execute("screen", screen)
+		if err != nil {
+			return err
+		}
+		data.Screens = append(data.Screens, html)
+	}
+	return r.t.ExecuteTemplate(w, "form", data)
+}
+
+// execute returns the output of a template, that is safe HTML.
+func (r *FormRenderer) execute(name string, data interface{}) (template.HTML, error) {
+	var b bytes.Buffer
+	if err := r.t.ExecuteTemplate(&b, name, data); err != nil {
+		return "", err
+	}
+	return template.HTML(b.String()), nil
+}
+
+func newItemData(item core.FormItem, v interface{}, msg string) ItemData {
+	var (
+		meta = core.Meta(item.Meta)
+		d    = ItemData{
+			FormItem:    item,
+			ID:          "field-" + item.Name,
+			Label:       meta.String("label"),
+			Description: meta.String("description"),
+			InputType:   "text",
+			Selected:    make(map[string]bool),
+			Options:     meta.Strings("option"),
+			Error:       msg,
+		}
+	)
+	switch item.Type {
+	case "email", "number", "date":
+		d.InputType = item.Type
+	}
+	if d.Label == "" {
+		d.Label = item.Name
+	}
+	switch v := v.(type) {
+	case nil:
+	case []string:
+		for _, s := range v {
+			d.Selected[s] = true
+		}
+	case []interface{}:
+		for _, s := range v {
+			d.Selected[fmt.Sprint(s)] = true
+		}
+	default:
+		d.Value = fmt.Sprint(v)
+		d.Selected[d.Value] = true
+	}
+	if d.Description != "" {
+		d.DescribedBy = d.ID + "-description"
+	}
+	if d.Error != "" {
+		if d.DescribedBy != "" {
+			d.DescribedBy += " "
+		}
+		d.DescribedBy += d.ID + "-error"
+	}
+	return d
+}
+
+// Answers returns the answers to the Form in the posted values, the keys that
+// are not item names are ignored. Items with options of list kind have all
+// the values, the others the first one.
+func Answers(f *core.Form, v url.Values) map[string]interface{} {
+	answers := make(map[string]interface{})
+	for _, s := range f.Screens {
+		for _, item := range s.Items {
+			values, ok := v[item.Name]
+			if !ok || len(values) == 0 {
+				continue
+			}
+			if item.Kind() == "list" {
+				answers[item.Name] = values
+				continue
+			}
+			if values[0] != "" {
+				answers[item.Name] = values[0]
+			}
+		}
+	}
+	return answers
+}
+
+// Submit reads the answers from the posted values and validates them. If
+// there are errors, the Form is written again with them.
+func (r *FormRenderer) Submit(w io.Writer, f *core.Form, v url.Values) (map[string]interface{}, core.FieldErrors, error) {
+	answers := Answers(f, v)
+	errs := f.Validate(answers)
+	if errs == nil {
+		return answers, nil, nil
+	}
+	return answers, errs, r.Render(w, f, answers, errs)
+}
+
+const formTemplates = `
+{{- define "form" -}}
+<form method="post" novalidate>
+{{- if .Errors}}
+<div class="form-errors" role="alert">
+{{- range .Errors}}
+<p>{{.}}</p>
+{{- end}}
+</div>
+{{- end}}
+{{- range .Screens}}
+{{.}}
+{{- end}}
+<button type="submit">Submit</button>
+</form>
+{{end -}}
+
+{{- define "screen" -}}
+<fieldset class="screen" id="screen-{{.Index}}"
+{{- with .Meta.name}} data-name="{{.}}"{{end}}
+{{- range .NextScreenIf}} data-next-screen-if="{{.If}}" data-next-screen="{{.Screen}}"{{end}}>
+{{- with .Title}}
+<legend>{{.}}</legend>
+{{- end}}
+{{- range .Items}}
+{{.}}
+{{- end}}
+</fieldset>
+{{- end}}
+
+{{- define "label" -}}
+{{.Label}}{{if .Required}}<span class="required" aria-hidden="true">*</span>{{end}}
+{{- end}}
+
+{{- define "attrs" -}}
+{{if .Mandatory}} required aria-required="true"{{end}}
+{{- if .Error}} aria-invalid="true"{{end}}
+{{- with .DescribedBy}} aria-describedby="{{.}}"{{end}}
+{{- end}}
+
+{{- define "messages" -}}
+{{with .Description}}
+<p class="description" id="{{$.ID}}-description">{{.}}</p>
+{{- end}}
+{{- with .Error}}
+<p class="error" id="{{$.ID}}-error" role="alert">{{.}}</p>
+{{- end}}
+{{- end}}
+
+{{- define "open" -}}
+<div class="item item-{{.Type}}{{if .Error}} invalid{{end}}"{{with .ShowIf}} data-show-if="{{.}}"{{end}}>
+{{- end}}
+
+{{- define "item" -}}
+{{template "open" .}}
+<label for="{{.ID}}">{{template "label" .}}</label>
+<input type="{{.InputType}}" id="{{.ID}}" name="{{.Name}}" value="{{.Value}}"
+{{- if eq .InputType "number" "date"}}{{with .Meta.min}} min="{{.}}"{{end}}{{with .Meta.max}} max="{{.}}"{{end}}
+{{- else}}{{with .Meta.min}} minlength="{{.}}"{{end}}{{with .Meta.max}} maxlength="{{.}}"{{end}}{{end}}
+{{- with .Meta.pattern}} pattern="{{.}}"{{end}}
+{{- with .Meta.placeholder}} placeholder="{{.}}"{{end}}
+{{- template "attrs" .}}>
+{{- template "messages" .}}
+</div>
+{{- end}}
+
+{{- define "item:select" -}}
+{{template "open" .}}
+<label for="{{.ID}}">{{template "label" .}}</label>
+<select id="{{.ID}}" name="{{.Name}}"{{template "attrs" .}}>
+<option value="">{{with .Meta.placeholder}}{{.}}{{end}}</option>
+{{- range .Options}}
+<option value="{{.}}"{{if index $.Selected .}} selected{{end}}>{{.}}</option>
+{{- end}}
+</select>
+{{- template "messages" .}}
+</div>
+{{- end}}
+
+{{- define "choices" -}}
+{{template "open" .}}
+<fieldset id="{{.ID}}"{{with .DescribedBy}} aria-describedby="{{.}}"{{end}}{{if .Error}} aria-invalid="true"{{end}}>
+<legend>{{template "label" .}}</legend>
+{{- range $i, $o := .Options}}
+<input type="{{if eq $.Type "radio"}}radio{{else}}checkbox{{end}}" id="{{$.ID}}-{{$i}}" name="{{$.Name}}" value="{{$o}}"
+{{- if index $.Selected $o}} checked{{end}}{{if and $.Mandatory (eq $.Type "radio")}} required{{end}}>
+<label for="{{$.ID}}-{{$i}}">{{$o}}</label>
+{{- end}}
+</fieldset>
+{{- template "messages" .}}
+</div>
+{{- end}}
+
+{{- define "item:radio"}}{{template "choices" .}}{{end}}
+
+{{- define "item:checkbox" -}}
+{{if .Options}}{{template "choices" .}}{{else -}}
+{{template "open" .}}
+<input type="checkbox" id="{{.ID}}" name="{{.Name}}"{{if or (index .Selected "on") (index .Selected "true")}} checked{{end}}{{template "attrs" .}}>
+<label for="{{.ID}}">{{template "label" .}}</label>
+{{- template "messages" .}}
+</div>
+{{- end}}
+{{- end}}
+`
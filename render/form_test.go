@@ -0,0 +1,86 @@
+package render
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-tent/tent/core"
+)
+
+func testForm() *core.Form {
+	return &core.Form{Screens: []core.FormScreen{
+		{Meta: core.Map{"title": "You"}, Items: []core.FormItem{
+			{Name: "name", Type: "text", Required: true, Meta: core.Map{"label": "Name", "max": 5, "description": "Your name"}},
+			{Name: "size", Type: "radio", Required: true, Meta: core.Map{"option": []interface{}{"s", "m"}}},
+			{Name: "tags", Type: "checkbox", ShowIf: "size == 'm'", Meta: core.Map{"option": []interface{}{"a", "b"}}},
+		}},
+		{Items: []core.FormItem{
+			{Name: "color", Type: "select", Required: true, Meta: core.Map{"option": []interface{}{"red", "blue"}}},
+			{Name: "agree", Type: "checkbox"},
+		}},
+	}}
+}
+
+func TestFormRender(t *testing.T) {
+	var (
+		f = testForm()
+		r = NewFormRenderer()
+		b bytes.Buffer
+	)
+	answers, errs, err := r.Submit(&b, f, url.Values{"name": {"robert"}, "size": {"m"}, "tags": {"a", "b"}, "color": {"red"}, "csrf": {"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]interface{}{"name": "robert", "size": "m", "tags": []string{"a", "b"}, "color": "red"}
+	if !reflect.DeepEqual(answers, exp) {
+		t.Fatalf("Expected %v, got %v", exp, answers)
+	}
+	if len(errs) != 1 || errs[0].Field != "name" {
+		t.Fatalf("Expected error for %v, got %v", "name", errs)
+	}
+	html := b.String()
+	for _, exp := range []string{
+		`<fieldset class="screen" id="screen-0">`,
+		`<legend>You</legend>`,
+		`<label for="field-name">Name<span class="required" aria-hidden="true">*</span></label>`,
+		`value="robert" maxlength="5" required aria-required="true" aria-invalid="true" aria-describedby="field-name-description field-name-error">`,
+		`<p class="error" id="field-name-error" role="alert">must be at most 5 characters</p>`,
+		`<input type="radio" id="field-size-1" name="size" value="m" checked required>`,
+		`<div class="item item-checkbox" data-show-if="size == &#39;m&#39;">`,
+		`<input type="checkbox" id="field-tags-0" name="tags" value="a" checked>`,
+		`<option value="red" selected>red</option>`,
+		`<input type="checkbox" id="field-agree" name="agree">`,
+	} {
+		if !strings.Contains(html, exp) {
+			t.Fatalf("Expected %q in:\n%s", exp, html)
+		}
+	}
+	if strings.Count(html, "<fieldset class=\"screen\"") != 2 {
+		t.Fatalf("Expected 2 screens in:\n%s", html)
+	}
+
+	b.Reset()
+	if _, errs, err := r.Submit(&b, f, url.Values{"name": {"bob"}, "size": {"s"}, "color": {"blue"}, "agree": {"on"}}); err != nil || errs != nil || b.Len() != 0 {
+		t.Fatalf("Expected no errors, got %v %v %q", errs, err, b.String())
+	}
+}
+
+func TestFormTemplate(t *testing.T) {
+	r := NewFormRenderer()
+	if err := r.Template("item:text", `<input class="custom" name="{{.Name}}" value="{{.Value}}">`); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := r.Render(&b, testForm(), map[string]interface{}{"name": "<b>"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if exp := `<input class="custom" name="name" value="&lt;b&gt;">`; !strings.Contains(b.String(), exp) {
+		t.Fatalf("Expected %q in:\n%s", exp, b.String())
+	}
+	if err := r.Template("item:text", `{{.Missing`); err == nil {
+		t.Fatal("Expected error")
+	}
+}
@@ -0,0 +1,147 @@
+// Package render turns Segment bodies and Forms into HTML.
+//
+// The markdown is CommonMark with tables, footnotes and heading anchors. Raw
+// HTML and dangerous URLs (like javascript:) are removed from the output.
+// Relative links to other Segments and files are resolved in the content tree
+// and rewritten with the URL functions of the Options.
+//
+// Forms are written with html/template by a FormRenderer, that also validates
+// the posted answers.
+package render
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/go-tent/tent/core"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Options configure a Renderer.
+type Options struct {
+	// SegmentURL returns the URL of a linked Segment, p is its path in the
+	// tree (example: "guides/setup"). Links are not changed if nil.
+	SegmentURL func(p string) string
+	// FileURL returns the URL of a linked file, like a Picture, p is its path
+	// in the tree (example: "guides/diagram.png"). Links are not changed if nil.
+	FileURL func(p string) string
+}
+
+// Renderer converts markdown to HTML.
+type Renderer struct {
+	md   goldmark.Markdown
+	opts Options
+}
+
+// New returns a Renderer with the given Options.
+func New(opts Options) *Renderer {
+	r := Renderer{opts: opts}
+	r.md = goldmark.New(
+		goldmark.WithExtensions(extension.Table, extension.Footnote),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+			parser.WithASTTransformers(util.Prioritized(linkTransformer{&r}, 100)),
+		),
+	)
+	return &r
+}
+
+// Render writes the HTML of the Segment body, dir is the path of the
+// Category of the Segment, used to resolve relative links.
+func (r *Renderer) Render(w io.Writer, dir string, s *core.Segment) error {
+	ctx := parser.NewContext(parser.WithIDs(make(core.Anchors)))
+	ctx.Set(dirKey, dir)
+	return r.md.Convert(s.Body, w, parser.WithContext(ctx))
+}
+
+// HTML returns the HTML of the Segment body, see Render.
+func (r *Renderer) HTML(dir string, s *core.Segment) ([]byte, error) {
+	b := bytes.NewBuffer(nil)
+	if err := r.Render(b, dir, s); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+var dirKey = parser.NewContextKey()
+
+// linkTransformer rewrites the relative destinations of links and images.
+type linkTransformer struct{ r *Renderer }
+
+func (t linkTransformer) Transform(doc *ast.Document, _ text.Reader, pc parser.Context) {
+	dir, _ := pc.Get(dirKey).(string)
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			n.Destination = t.r.rewrite(dir, n.Destination)
+		case *ast.Image:
+			n.Destination = t.r.rewrite(dir, n.Destination)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// rewrite returns the URL for a link destination.
+func (r *Renderer) rewrite(dir string, dest []byte) []byte {
+	target, ok := Resolve(dir, string(dest))
+	if !ok {
+		return dest
+	}
+	var u string
+	switch {
+	case target.Segment && r.opts.SegmentURL != nil:
+		u = r.opts.SegmentURL(target.Path)
+	case !target.Segment && r.opts.FileURL != nil:
+		u = r.opts.FileURL(target.Path)
+	default:
+		return dest
+	}
+	if target.Fragment != "" {
+		u += "#" + target.Fragment
+	}
+	return []byte(u)
+}
+
+// Target is the destination of a relative link.
+type Target struct {
+	// Path is the location in the tree: the ID path for Segments, the file
+	// path for the others.
+	Path     string
+	Fragment string
+	// Segment tells if the target is a Segment.
+	Segment bool
+}
+
+// Resolve returns the Target of a link in a Segment of the Category at dir,
+// ok is false for absolute URLs, fragments and links outside the tree.
+func Resolve(dir, dest string) (t Target, ok bool) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return Target{}, false
+	}
+	p := path.Join(dir, u.Path)
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return Target{}, false
+	}
+	t = Target{Path: p, Fragment: u.Fragment}
+	pre, exts := (*core.Segment).Format(nil)
+	if base := path.Base(p); strings.HasPrefix(base, pre) && path.Ext(base) == exts[0] {
+		t.Path = path.Join(path.Dir(p), strings.TrimSuffix(strings.TrimPrefix(base, pre), exts[0]))
+		t.Segment = true
+	}
+	return t, true
+}
+
+// Anchor returns the slug of a heading, the same as core.Anchor.
+func Anchor(heading string) string { return core.Anchor(heading) }
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-tent/tent/core"
+)
+
+func TestRender(t *testing.T) {
+	r := New(Options{
+		SegmentURL: func(p string) string { return "/docs/" + p + "/" },
+		FileURL:    func(p string) string { return "/static/" + p },
+	})
+	s := &core.Segment{ID: "intro", Body: []byte(`# Getting Started
+
+See [setup](../setup/s_install.md#linux), [next](s_next.md) and [site](https://example.com).
+
+![diagram](img/flow.png)
+
+| a | b |
+|---|---|
+| 1 | 2 |
+
+Text[^1] <script>alert(1)</script> [bad](javascript:alert(1))
+
+## Getting Started
+
+[^1]: A note.
+`)}
+	b, err := r.HTML("guides/basics", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(b)
+	for _, exp := range []string{
+		`<h1 id="getting-started">Getting Started</h1>`,
+		`<h2 id="getting-started-1">Getting Started</h2>`,
+		`<a href="/docs/guides/setup/install/#linux">setup</a>`,
+		`<a href="/docs/guides/basics/next/">next</a>`,
+		`<a href="https://example.com">site</a>`,
+		`<img src="/static/guides/basics/img/flow.png" alt="diagram">`,
+		`<td>1</td>`,
+		`class="footnote-ref"`,
+	} {
+		if !strings.Contains(html, exp) {
+			t.Fatalf("Expected %q in:\n%s", exp, html)
+		}
+	}
+	for _, unexp := range []string{"<script>", "javascript:"} {
+		if strings.Contains(html, unexp) {
+			t.Fatalf("Unexpected %q in:\n%s", unexp, html)
+		}
+	}
+	for _, h := range s.Outline() {
+		for _, h := range append([]core.Heading{h}, h.Sub...) {
+			if exp := `id="` + h.Anchor + `"`; !strings.Contains(html, exp) {
+				t.Fatalf("Expected %q in:\n%s", exp, html)
+			}
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	for dest, exp := range map[string]Target{
+		"s_a.md":         {Path: "x/y/a", Segment: true},
+		"../s_b.md#top":  {Path: "x/b", Fragment: "top", Segment: true},
+		"pic.png":        {Path: "x/y/pic.png"},
+		"../../../a.png": {},
+		"/a.png":         {},
+		"#top":           {},
+		"http://a.com/b": {},
+	} {
+		got, ok := Resolve("x/y", dest)
+		if ok != (exp.Path != "") || got != exp {
+			t.Fatalf("%s: expected %+v, got %+v", dest, exp, got)
+		}
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	for in, exp := range map[string]string{
+		"Hello, World!":    "hello-world",
+		"  **Über** Café ": "über-café",
+		"v1.2 release":     "v1-2-release",
+		"!!!":              "",
+	} {
+		if got := Anchor(in); got != exp {
+			t.Fatalf("%q: expected %q, got %q", in, exp, got)
+		}
+	}
+}
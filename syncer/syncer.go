@@ -0,0 +1,218 @@
+// Package syncer reconciles a Source with a Destination.
+package syncer
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-tent/tent/destination"
+	"github.com/go-tent/tent/item"
+	"github.com/go-tent/tent/source"
+)
+
+// Action is the operation needed to reconcile an Item.
+type Action int
+
+// Available Actions.
+const (
+	Noop Action = iota
+	Create
+	Update
+	Delete
+)
+
+func (a Action) String() string {
+	switch a {
+	case Noop:
+		return "noop"
+	case Create:
+		return "create"
+	case Update:
+		return "update"
+	case Delete:
+		return "delete"
+	default:
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+}
+
+// HashFunc returns the hash of the contents, as computed by a Destination.
+type HashFunc func([]byte) string
+
+// SHA1 is the HashFunc used by File and Memory destinations.
+func SHA1(b []byte) string {
+	h := sha1.Sum(b)
+	return hex.EncodeToString(h[:])
+}
+
+// GitBlob is the HashFunc used by GithubAPI destination.
+func GitBlob(b []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(b))
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Step is a planned Action on an Item.
+type Step struct {
+	Action Action
+	Item   item.Item
+	// Hash is the one in the Destination, empty for Create.
+	Hash string
+}
+
+func (s Step) String() string {
+	return fmt.Sprintf("%s %s", s.Action, s.Item.Name())
+}
+
+// Plan is the list of Steps that reconcile a Source with a Destination.
+type Plan []Step
+
+// Count returns the number of Steps with the given Action.
+func (p Plan) Count(a Action) int {
+	var n int
+	for _, s := range p {
+		if s.Action == a {
+			n++
+		}
+	}
+	return n
+}
+
+// Result is the outcome of a Step.
+type Result struct {
+	Step
+	Err error
+}
+
+func (r Result) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %s", r.Step, r.Err)
+	}
+	return r.Step.String()
+}
+
+// Report is the list of Results of an applied Plan.
+type Report []Result
+
+// Failed returns the Results with an error.
+func (r Report) Failed() Report {
+	var f Report
+	for _, v := range r {
+		if v.Err != nil {
+			f = append(f, v)
+		}
+	}
+	return f
+}
+
+// New returns a Syncer for the given Destination, if hash is nil SHA1 is used.
+func New(dst destination.Destination, hash HashFunc) *Syncer {
+	if hash == nil {
+		hash = SHA1
+	}
+	return &Syncer{dst: dst, hash: hash}
+}
+
+// Syncer reconciles Sources with a Destination.
+type Syncer struct {
+	dst  destination.Destination
+	hash HashFunc
+}
+
+// Plan compares src with the Destination. Items listed by current (the items
+// already in the Destination) that are missing in src are deleted; if current
+// is nil nothing is deleted.
+func (s *Syncer) Plan(ctx context.Context, src, current source.Source) (Plan, error) {
+	var (
+		plan Plan
+		seen = make(map[string]bool)
+	)
+	for i, err := src.Next(); i != nil; i, err = src.Next() {
+		if err != nil {
+			return nil, err
+		}
+		step, err := s.step(ctx, i)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", i.Name(), err)
+		}
+		seen[i.Name()] = true
+		plan = append(plan, step)
+	}
+	if current == nil {
+		return plan, nil
+	}
+	for i, err := current.Next(); i != nil; i, err = current.Next() {
+		if err != nil {
+			return nil, err
+		}
+		if seen[i.Name()] {
+			continue
+		}
+		hash, err := s.dst.Hash(ctx, i)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("%s: %s", i.Name(), err)
+		}
+		plan = append(plan, Step{Action: Delete, Item: i, Hash: hash})
+	}
+	return plan, nil
+}
+
+func (s *Syncer) step(ctx context.Context, i item.Item) (Step, error) {
+	r, err := i.Content()
+	if err != nil {
+		return Step{}, err
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Step{}, err
+	}
+	step := Step{Item: item.Memory{ID: i.Name(), Contents: b}}
+	hash, err := s.dst.Hash(ctx, i)
+	switch {
+	case os.IsNotExist(err):
+		step.Action = Create
+	case err != nil:
+		return Step{}, err
+	case hash != s.hash(b):
+		step.Action, step.Hash = Update, hash
+	default:
+		step.Action, step.Hash = Noop, hash
+	}
+	return step, nil
+}
+
+// Apply executes the Plan, failing Steps do not stop the following ones.
+func (s *Syncer) Apply(ctx context.Context, p Plan) Report {
+	report := make(Report, 0, len(p))
+	for _, step := range p {
+		var err error
+		switch step.Action {
+		case Create:
+			err = s.dst.Create(ctx, step.Item)
+		case Update:
+			err = s.dst.Update(ctx, step.Item, step.Hash)
+		case Delete:
+			err = s.dst.Delete(ctx, step.Item, step.Hash)
+		}
+		report = append(report, Result{Step: step, Err: err})
+	}
+	return report
+}
+
+// Sync plans and applies the reconciliation of src with the Destination.
+func (s *Syncer) Sync(ctx context.Context, src, current source.Source) (Report, error) {
+	plan, err := s.Plan(ctx, src, current)
+	if err != nil {
+		return nil, err
+	}
+	return s.Apply(ctx, plan), nil
+}
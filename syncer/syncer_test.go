@@ -0,0 +1,86 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-tent/tent/destination"
+	"github.com/go-tent/tent/item"
+	"github.com/go-tent/tent/source"
+)
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	dst := new(destination.Memory)
+	for _, i := range []item.Memory{
+		{ID: "same", Contents: []byte("a")},
+		{ID: "changed", Contents: []byte("b")},
+		{ID: "removed", Contents: []byte("c")},
+	} {
+		if err := dst.Create(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src := &source.Memory{Items: []item.Memory{
+		{ID: "same", Contents: []byte("a")},
+		{ID: "changed", Contents: []byte("B")},
+		{ID: "added", Contents: []byte("d")},
+	}}
+	current := &source.Memory{Items: []item.Memory{
+		{ID: "same"}, {ID: "changed"}, {ID: "removed"},
+	}}
+
+	s := New(dst, nil)
+	plan, err := s.Plan(ctx, src, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]Action{"same": Noop, "changed": Update, "added": Create, "removed": Delete}
+	if len(plan) != len(exp) {
+		t.Fatalf("Expected %d steps, got %v", len(exp), plan)
+	}
+	for _, step := range plan {
+		if a := exp[step.Item.Name()]; a != step.Action {
+			t.Errorf("%s: expected %s, got %s", step.Item.Name(), a, step.Action)
+		}
+	}
+
+	for _, r := range s.Apply(ctx, plan) {
+		if r.Err != nil {
+			t.Errorf("%s", r)
+		}
+	}
+	hash, err := dst.Hash(ctx, item.Memory{ID: "changed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := SHA1([]byte("B")); hash != exp {
+		t.Fatalf("Expected %s, got %s", exp, hash)
+	}
+	if _, err := dst.Hash(ctx, item.Memory{ID: "removed"}); err == nil {
+		t.Fatalf("Expected %q to be removed", "removed")
+	}
+}
+
+func TestApplyFailures(t *testing.T) {
+	ctx := context.Background()
+	dst := new(destination.Memory)
+	plan := Plan{
+		{Action: Update, Item: item.Memory{ID: "missing"}},
+		{Action: Create, Item: item.Memory{ID: "new"}},
+	}
+	report := New(dst, nil).Apply(ctx, plan)
+	if l := len(report); l != 2 {
+		t.Fatalf("Expected %d results, got %d", 2, l)
+	}
+	if f := report.Failed(); len(f) != 1 || f[0].Item.Name() != "missing" {
+		t.Fatalf("Expected %q to fail, got %v", "missing", f)
+	}
+}
+
+func TestGitBlob(t *testing.T) {
+	// git hash-object of "hello\n"
+	if h, exp := GitBlob([]byte("hello\n")), "ce013625030ba8dba906f756967f9e9ca394464a"; h != exp {
+		t.Fatalf("Expected %s, got %s", exp, h)
+	}
+}